@@ -4,13 +4,17 @@
 package accelhint
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
+	"regexp"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
-	"github.com/charles-haynes/munkres"
+	"github.com/rivo/uniseg"
 	"golang.org/x/exp/slices"
+	"golang.org/x/text/unicode/norm"
 )
 
 //go:embed Version.dat
@@ -18,17 +22,33 @@ var Version string
 
 type weights [][]float64
 
+type positions [][]int
+
 const (
 	Alphabet    = "ABCDEFGHIJKLMNOPQRSTUVWXYZ123456789" // MUST be UPPERCASE
 	Marker      = '&'
 	GtkMarker   = '_'
-	maxWeight   = 900100.0
 	placeholder = "||"
+
+	maxImportance  = 10                     // so claims stay whole
+	claimUnit      = 1 << maxImportance     // a claim of 1 (see updateWeights)
+	maxClaim       = 1 << 28                // more than any claim
+	placeBits      = 23                     // see setWeights
+	weightUnit     = claimUnit << placeBits // a claim of 1 in a weight
+	maxWeight      = maxClaim << placeBits  // 2^51, so float64s are exact
+	maxPlace       = 1 << placeBits         // more than all the places' sum
+	maxTieRows     = 1000                   // see canonicalize
+	preferredBonus = 50                     // units
 )
 
+// An alphabet for Options.CaseSensitive with both cases of every letter.
+const CaseSensitiveAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ" +
+	"abcdefghijklmnopqrstuvwxyz123456789"
+
 // Returns items with '&'s to indicate accelerators, and the number
 // accelerated. Only characters in the Alphabet are candidates. Use '&&' for
-// literal '&'s.
+// literal '&'s. Any text from a ShortcutSeparator onwards is ignored, and
+// empty items and separators (e.g., "-") are returned unchanged.
 // See also HintedX.
 func Hinted(items []string) ([]string, int, error) {
 	return HintedX(items, Marker, Alphabet)
@@ -38,22 +58,13 @@ func Hinted(items []string) ([]string, int, error) {
 // accelerators with characters from the given alphabet (of unique uppercase
 // characters) as candidates, and how many were accelerated. Use marker +
 // marker for literal markers.
-// See also Hinted.
+// See also Hinted and Options.
 func HintedX(items []string, marker byte, alphabet string) ([]string,
 	int, error) {
-	lines := normalized(items, marker)
-	alphabetChars := []rune(alphabet)
-	weights, err := getWeights(lines, marker, alphabetChars)
-	if err != nil {
-		return nil, 0, err
-	}
-	m, err := munkres.NewHungarianAlgorithm(weights)
-	if err != nil {
-		return nil, 0, err
-	}
-	indexes := m.Execute()
-	lines, count, err := applyIndexes(items, marker, alphabetChars, indexes)
-	return lines, count, err
+	options := NewOptions()
+	options.Marker = marker
+	options.Alphabet = alphabet
+	return options.Hinted(items)
 }
 
 // Returns the accelerated chars from the hinted strings assuming '&' is the
@@ -72,102 +83,603 @@ func AcceleratorsX(hinted []string, marker byte) []rune {
 	chars := make([]rune, 0, len(hinted))
 	for _, hint := range hinted {
 		hint = strings.ReplaceAll(hint, mm, placeholder)
-		hintChars := []rune(hint)
-		i := slices.Index(hintChars, rune(marker))
-		if i == -1 || i+1 == len(hintChars) {
-			chars = append(chars, 0)
-		} else {
-			chars = append(chars, hintChars[i+1])
-		}
+		chars = append(chars, acceleratedChar(hint, marker))
 	}
 	return chars
 }
 
-func normalized(items []string, marker byte) []string {
-	lines := make([]string, 0, len(items))
-	m := string(marker)
-	mm := m + m
-	for _, line := range items {
-		lines = append(lines, strings.ReplaceAll(line, mm, placeholder))
+// assignment holds the weights and positions for the items being
+// considered that have candidates (one row each) and the alphabet chars
+// that are candidates (one column each), and the resulting column (or -1)
+// for every item. The remaining fields are buffers that are reused if the
+// assignment is (e.g., by a Hinter).
+type assignment struct {
+	weights   weights
+	positions positions
+	rows      []int // the item index of each row
+	keys      []int // the alphabet index of each column
+	columns   []int // the column assigned to each item
+
+	considered    []int // the item index of each item considered
+	itemClaims    []int64
+	itemColumns   []int
+	itemPositions []int
+	used          []int // 1 if an alphabet char is a candidate, then column
+	cells         []cell
+	ends          []int // the end of each row's cells
+	weightsData   []float64
+	positionsData []int
+	indexes       []int
+	chars         []rune
+	starts        []bool
+	excluded      []bool
+	seen          map[rune]int
+	potentials    []int64
+	owners        []int
+	parents       []int
+	queue         []int
+	solver        jvBuffers // for either JonkerVolgenant Assigner
+}
+
+// Returns the assignment of columns to items.
+func assign(items []string, options *Options, alphabet []rune) (
+	*assignment, error) {
+	assignment := &assignment{}
+	err := assignment.assign(context.Background(), items, options, alphabet)
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// Sets the assignment of columns to items, or returns the context's error
+// if it is done while the weights are being set or before they are solved.
+func (assignment *assignment) assign(ctx context.Context, items []string,
+	options *Options, alphabet []rune) error {
+	err := assignment.getWeights(ctx, items, options, alphabet)
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	assignment.columns = resized(assignment.columns, len(items))
+	for i := range assignment.columns {
+		assignment.columns[i] = -1
+	}
+	if len(assignment.rows) == 0 {
+		return nil
+	}
+	assigner := options.Assigner
+	if assigner == nil {
+		assigner = Hungarian
+	}
+	assignment.indexes = resized(assignment.indexes, len(assignment.rows))
+	switch assigner.(type) {
+	case jonkerVolgenant, *jonkerVolgenant: // solve with reused buffers
+		err = assignment.solver.assign(assignment.weights,
+			assignment.indexes)
+	default:
+		err = assigner.Assign(assignment.weights, assignment.indexes)
+	}
+	if err != nil {
+		return err
+	}
+	assignment.canonicalize()
+	for row, column := range assignment.indexes {
+		assignment.columns[assignment.rows[row]] = column
+	}
+	return nil
+}
+
+// Changes the assignment (if it is optimal) to the one that every optimal
+// assignment of the same weights would be changed to, so that the result
+// doesn't depend on the Assigner. Each row in turn gets its best column
+// (by weight, then by column) of those that still allow an optimal
+// assignment of the later rows. Optimal assignments are exactly those
+// that only use tight columns (see setPotentials) and that use every
+// column with a potential above zero, so each change is a cycle of such
+// moves. Does nothing if the assignment isn't optimal (e.g., it was made
+// by Greedy), or if there are more than maxTieRows rows.
+func (assignment *assignment) canonicalize() {
+	weights, columnFor := assignment.weights, assignment.indexes
+	rows := len(weights)
+	if rows == 0 || rows > maxTieRows {
+		return
+	}
+	columns := len(weights[0])
+	assignment.owners = resized(assignment.owners, columns)
+	owners := assignment.owners
+	for column := range owners {
+		owners[column] = -1
+	}
+	for row, column := range columnFor {
+		if column < 0 || owners[column] != -1 {
+			return
+		}
+		owners[column] = row
+	}
+	if !assignment.setPotentials() {
+		return
+	}
+	better := func(row, column, other int) bool {
+		weight, otherWeight := weights[row][column], weights[row][other]
+		return weight < otherWeight ||
+			(weight == otherWeight && column < other)
+	}
+	for row := 0; row < rows; row++ {
+		tried := -1
+		for {
+			next := -1 // the best untried column better than the current
+			for column := 0; column < columns; column++ {
+				if assignment.tight(row, column) &&
+					better(row, column, columnFor[row]) &&
+					(tried == -1 || better(row, tried, column)) &&
+					(next == -1 || better(row, column, next)) {
+					next = column
+				}
+			}
+			if next == -1 || assignment.reassign(row, next) {
+				break
+			}
+			tried = next
+		}
+	}
+}
+
+// Sets the column potentials for the (optimal) assignment and returns true,
+// or returns false if the assignment isn't optimal. The potentials are the
+// shortest distances from the unassigned columns, where a row's move from
+// its column to another is an edge from the other to its own of the
+// difference in their weights. A column is tight for a row if its weight
+// plus its potential equals that of the row's column, and these moves
+// are the only ones that keep an assignment optimal.
+func (assignment *assignment) setPotentials() bool {
+	weights, columnFor := assignment.weights, assignment.indexes
+	rows, columns := len(weights), len(weights[0])
+	assignment.potentials = resized(assignment.potentials, columns)
+	potentials := assignment.potentials
+	unreached := int64(2*rows+2) * maxWeight // more than any distance
+	for column, owner := range assignment.owners {
+		potentials[column] = 0
+		if owner != -1 {
+			potentials[column] = unreached
+		}
 	}
-	return lines
+	for round := 0; ; round++ {
+		changed := false
+		for row, own := range columnFor {
+			weight := int64(weights[row][own])
+			for column, other := range weights[row] {
+				distance := potentials[column] + int64(other) - weight
+				if distance < potentials[own] {
+					potentials[own] = distance
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+		if round == columns { // a negative cycle would improve it
+			return false
+		}
+	}
+	for _, potential := range potentials {
+		if potential < 0 { // a move to an unassigned column would improve it
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if the column is tight for the row (see setPotentials).
+func (assignment *assignment) tight(row, column int) bool {
+	own := assignment.indexes[row]
+	return int64(assignment.weights[row][column])+
+		assignment.potentials[column] ==
+		int64(assignment.weights[row][own])+assignment.potentials[own]
 }
 
-func getWeights(items []string, marker byte, alphabet []rune) (weights,
-	error) {
-	weights := makeMaxWeights(len(alphabet))
-	err := updateWeights(items, weights, rune(marker), alphabet)
-	return weights, err
+// Moves the row to the (tight) column and returns true if there's a cycle
+// of tight moves that ends by filling the row's own column and that doesn't
+// move any earlier row, or returns false. An unassigned column can be
+// filled by a move to any column with a potential of 0.
+func (assignment *assignment) reassign(row, column int) bool {
+	columnFor, owners := assignment.indexes, assignment.owners
+	own := columnFor[row]
+	assignment.parents = resized(assignment.parents, len(owners))
+	parents := assignment.parents // the column each was reached from
+	for i := range parents {
+		parents[i] = -2 // unreached
+	}
+	parents[column] = -1
+	queue := append(assignment.queue[:0], column)
+	defer func() { assignment.queue = queue }()
+	for i := 0; i < len(queue); i++ {
+		from := queue[i]
+		owner := owners[from]
+		if owner != -1 && owner < row {
+			continue
+		}
+		for to := range owners {
+			if parents[to] != -2 || (owner == -1 &&
+				assignment.potentials[to] != 0) || (owner != -1 &&
+				!assignment.tight(owner, to)) {
+				continue
+			}
+			parents[to] = from
+			if to == own {
+				for ; parents[to] != -1; to = parents[to] {
+					mover := owners[parents[to]]
+					owners[to] = mover
+					if mover != -1 {
+						columnFor[mover] = to
+					}
+				}
+				owners[column] = row
+				columnFor[row] = column
+				return true
+			}
+			queue = append(queue, to)
+		}
+	}
+	return false
+}
+
+// cell is a candidate alphabet char for an item, with the item's claim on
+// it, and its (grapheme cluster) column and (rune) position in the item.
+type cell struct {
+	index    int // in the alphabet
+	claim    int64
+	column   int
+	position int
+}
+
+// Sets a rectangular matrix of weights (and of positions) with a row for
+// each item that has candidates and a column for each alphabet char that is
+// a candidate for at least one item. Returns the context's error if it is
+// done (checked before each item).
+func (assignment *assignment) getWeights(ctx context.Context,
+	items []string, options *Options, alphabet []rune) error {
+	size := len(alphabet)
+	assignment.itemClaims = resized(assignment.itemClaims, size)
+	assignment.itemColumns = resized(assignment.itemColumns, size)
+	assignment.itemPositions = resized(assignment.itemPositions, size)
+	assignment.used = resized(assignment.used, size)
+	claims := assignment.itemClaims // for one item at a time
+	columns := assignment.itemColumns
+	positions := assignment.itemPositions
+	keys := assignment.used // the column of each alphabet char
+	for j := range keys {
+		keys[j] = 0
+	}
+	assignment.rows = assignment.rows[:0]
+	assignment.keys = assignment.keys[:0]
+	assignment.cells = assignment.cells[:0]
+	assignment.ends = assignment.ends[:0]
+	assignment.considered = options.rows(assignment.considered[:0], items,
+		size)
+	for _, row := range assignment.considered {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for j := range claims {
+			claims[j] = 0
+			positions[j] = -1
+		}
+		err := assignment.updateWeights(items[row], options, alphabet)
+		if err != nil {
+			return err
+		}
+		start := len(assignment.cells)
+		for j, claim := range claims {
+			if claim > 0 {
+				assignment.cells = append(assignment.cells,
+					cell{j, claim, columns[j], positions[j]})
+				keys[j] = 1
+			}
+		}
+		if len(assignment.cells) > start {
+			assignment.rows = append(assignment.rows, row)
+			assignment.ends = append(assignment.ends, len(assignment.cells))
+		}
+	}
+	used := 0
+	for _, key := range keys {
+		used += key
+	}
+	for j, key := range keys {
+		// If there are more rows than candidate chars keep every char so
+		// that each row is assigned a column (as for a square matrix)
+		if key == 1 || used < len(assignment.rows) {
+			keys[j] = len(assignment.keys)
+			assignment.keys = append(assignment.keys, j)
+		}
+	}
+	assignment.makeMaxWeights(len(assignment.rows), len(assignment.keys))
+	assignment.setWeights()
+	return nil
 }
 
-func makeMaxWeights(size int) weights {
-	weights := make(weights, 0, size)
-	for row := 0; row < size; row++ {
-		weights = append(weights, make([]float64, 0, size))
-		for column := 0; column < size; column++ {
-			weights[row] = append(weights[row], maxWeight)
+// Sets each candidate's weight to maxWeight less the item's claim on the
+// char, plus its place: the char's column (clamped so it fits), times more
+// than the sum of the row places, plus the row's place, which is the
+// number of rows after it. The sum of the places of any assignment is less
+// than maxPlace, so the weights are ordered by claim, then by column
+// (preferring earlier chars), and then by row (preferring later items when
+// items compete for chars) without any of these outweighing another.
+func (assignment *assignment) setWeights() {
+	rows := len(assignment.rows)
+	if rows == 0 {
+		return
+	}
+	lastColumn := 0
+	for _, cell := range assignment.cells {
+		if cell.column > lastColumn {
+			lastColumn = cell.column
+		}
+	}
+	rowScale := int64(rows*(rows-1)/2 + 1) // more than the rows' sum
+	if rowScale > maxPlace/2 {             // too many rows to prefer any
+		rowScale = 1
+	}
+	lastColumn = clamp(lastColumn, 0, int((maxPlace/rowScale-1)/int64(rows)))
+	start := 0
+	for r, end := range assignment.ends {
+		rowPlace := int64(rows - 1 - r)
+		if rowScale == 1 {
+			rowPlace = 0
+		}
+		for _, cell := range assignment.cells[start:end] {
+			column := assignment.used[cell.index]
+			place := int64(clamp(cell.column, 0, lastColumn))*rowScale +
+				rowPlace
+			assignment.weights[r][column] = float64(
+				(maxClaim-cell.claim)<<placeBits + place)
+			assignment.positions[r][column] = cell.position
 		}
+		start = end
 	}
-	return weights
 }
 
-func updateWeights(items []string, weights weights, marker rune,
+// Sets the weights to a matrix of maxWeight weights and the positions to a
+// matching matrix of -1 positions.
+func (assignment *assignment) makeMaxWeights(rows, columns int) {
+	assignment.weightsData = resized(assignment.weightsData, rows*columns)
+	assignment.positionsData = resized(assignment.positionsData,
+		rows*columns)
+	for i := range assignment.weightsData {
+		assignment.weightsData[i] = maxWeight
+		assignment.positionsData[i] = -1
+	}
+	assignment.weights = resized(assignment.weights, rows)
+	assignment.positions = resized(assignment.positions, rows)
+	for row := 0; row < rows; row++ {
+		start, end := row*columns, (row+1)*columns
+		assignment.weights[row] = assignment.weightsData[start:end:end]
+		assignment.positions[row] = assignment.positionsData[start:end:end]
+	}
+}
+
+// Sets each item claim to the highest (i.e., best) claim of the item's
+// occurrences of the corresponding alphabet char (or leaves it 0 if there
+// are none), and sets the corresponding item column and position to the
+// (grapheme cluster) column and the (rune) index in the item of the
+// earliest such occurrence. Items are considered a grapheme cluster at a
+// time (each normalized as per the Options) so that markers are only ever
+// inserted before whole clusters in the original item, and format chars
+// (e.g., bidi marks) are skipped.
+//
+// Claims are whole numbers of 1/claimUnit units so there's no rounding to
+// differ between platforms: 99 units for a preset, 4 for the first char, 2
+// for a word start, and 1 anywhere else. A claim is then increased for
+// Preferred chars, and doubled (or halved, which is exact) for each step
+// of Importance.
+func (assignment *assignment) updateWeights(item string, options *Options,
 	alphabet []rune) error {
+	claims := assignment.itemClaims
+	columns := assignment.itemColumns
+	positions := assignment.itemPositions
+	marker := rune(options.Marker)
 	m := string(marker)
 	mm := m + m
+	claim := int64(0)
 	prev := rune(0)
-	for row, item := range items {
-		if row == len(weights) {
-			break
+	key := "" // only needed (and only made) for the Options' maps
+	if options.keyed() {
+		key = options.key(item)
+	}
+	preferred := options.Preferred[key]
+	forbidden := options.forbidden(key)
+	item = strings.ReplaceAll(withoutShortcut(item,
+		options.ShortcutSeparator), mm, placeholder)
+	chars := assignment.chars[:0]
+	for _, c := range item {
+		chars = append(chars, c)
+	}
+	assignment.chars = chars
+	assignment.starts = options.WordStarts(assignment.starts[:0], chars)
+	starts := assignment.starts
+	assignment.excluded = placeholderChars(assignment.excluded[:0], item,
+		options.Placeholders)
+	excluded := assignment.excluded
+	i := 0 // rune index of the current grapheme cluster
+	state := -1
+	var cluster string
+	start := false // true if a run of format chars is at a word start
+	for column := 0; item != ""; column++ {
+		cluster, item, _, state = uniseg.FirstGraphemeClusterInString(item,
+			state)
+		if isFormat(cluster) { // e.g., a bidi mark: skip & don't count it
+			start = start || starts[i]
+			i += utf8.RuneCountInString(cluster)
+			column--
+			continue
 		}
-		weight := 0.0
-		item = strings.ToUpper(strings.ReplaceAll(item, mm, placeholder))
-		for column, c := range item {
-			i := slices.Index(alphabet, c)
-			if i > -1 { // c in alphabet
-				if prev == marker { // preset
-					weight = maxWeight - 99.0
-				} else if column == 0 { // first
-					weight = maxWeight - 4.0
-				} else if unicode.IsSpace(prev) { // word start
-					weight = maxWeight - 2.0
-				} else { // anywhere
-					weight = maxWeight - 1.0
-				}
-				// slightly prefer earlier column & later row
-				weight += (float64(column) / 1100.0) - (float64(row) /
-					1000.0)
-				if weights[row][i] > weight {
-					weights[row][i] = weight
-				}
+		c, candidate := clusterChar(options.Normalization.normalize(
+			cluster), options.BaseLetters || options.FoldDiacritics)
+		forbid := strings.ContainsRune(forbidden, c)
+		c = options.upper(c)
+		j := slices.Index(alphabet, c)
+		if j == -1 && candidate && options.FoldDiacritics {
+			j = slices.Index(alphabet, folded(c))
+		}
+		if candidate && j > -1 && !excluded[i] && !forbid { // c in alphabet
+			if prev == marker { // preset
+				claim = 99 * claimUnit
+				preferred = ""
+			} else if column == 0 { // first
+				claim = 4 * claimUnit
+			} else if starts[i] || start { // word start
+				claim = 2 * claimUnit
+			} else { // anywhere
+				claim = 1 * claimUnit
+			}
+			if claims[j] < claim { // so the earliest of equal claims wins
+				claims[j] = claim
+				columns[j] = column
+				positions[j] = i
+			}
+		}
+		prev = c
+		start = false
+		i += utf8.RuneCountInString(cluster)
+	}
+	for k, c := range []rune(options.uppers(preferred)) {
+		j := slices.Index(alphabet, c)
+		if j > -1 && claims[j] > 0 {
+			claims[j] += (preferredBonus - int64(k)) * claimUnit
+		}
+	}
+	if importance := options.Importance[key]; importance != 0 {
+		importance = clamp(importance, -maxImportance, maxImportance)
+		for j := range claims {
+			if importance > 0 {
+				claims[j] <<= importance
+			} else {
+				claims[j] >>= -importance
 			}
-			prev = c
 		}
 	}
 	return nil
 }
 
-func applyIndexes(items []string, marker byte, alphabet []rune,
-	indexes []int) ([]string, int, error) {
+// Returns the grapheme cluster's char and true if it is a single char, or
+// if baseLetters is true and it is a char followed only by combining marks
+// (e.g., "e\u0301"). Otherwise returns the cluster's first char and false.
+func clusterChar(cluster string, baseLetters bool) (rune, bool) {
+	c, size := utf8.DecodeRuneInString(cluster)
+	if size == len(cluster) {
+		return c, true
+	}
+	if baseLetters {
+		for _, mark := range cluster[size:] {
+			if !unicode.Is(unicode.M, mark) {
+				return c, false
+			}
+		}
+		return c, true
+	}
+	return c, false
+}
+
+// Returns true if the grapheme cluster is a format char (category Cf),
+// e.g., a bidi mark, isolate, or embedding. These are never candidates and
+// are ignored when scoring the chars around them, so the marker for a
+// letter that follows them is inserted after them, right before the letter.
+func isFormat(cluster string) bool {
+	c, _ := utf8.DecodeRuneInString(cluster)
+	return isFormatChar(c)
+}
+
+// Returns true if the char is a format char (see isFormat).
+func isFormatChar(c rune) bool {
+	return c >= 0x80 && unicode.Is(unicode.Cf, c)
+}
+
+// Returns the char accelerated by the first marker in the text, or 0 if
+// there's none (see markedChar).
+func acceleratedChar(text string, marker byte) rune {
+	if i := strings.IndexByte(text, marker); i > -1 {
+		return markedChar(text[i+1:])
+	}
+	return 0
+}
+
+// Returns the first char of the text after a marker that isn't a format
+// char, since that's the char the marker accelerates (e.g., in "&\u200fX"
+// it's 'X'), or 0 if there's none.
+func markedChar(text string) rune {
+	for _, c := range text {
+		if !isFormatChar(c) {
+			return c
+		}
+	}
+	return 0
+}
+
+// Returns the char without its diacritics, i.e., the letter it canonically
+// decomposes into if that's followed only by combining marks (e.g., 'É' →
+// 'E' and 'Ñ' → 'N'), or else the char itself.
+func folded(c rune) rune {
+	if c < utf8.RuneSelf {
+		return c
+	}
+	decomposed := norm.NFD.String(string(c))
+	if letter, ok := clusterChar(decomposed, true); ok {
+		return letter
+	}
+	return c
+}
+
+func clamp(value, lowest, highest int) int {
+	if value < lowest {
+		return lowest
+	}
+	if value > highest {
+		return highest
+	}
+	return value
+}
+
+// Returns the items with the marker inserted at the position of each
+// item's assigned column (unless the item has a preset), and the number of
+// items accelerated.
+func applyIndexes(items []string, options *Options, alphabet []rune,
+	assignment *assignment) ([]string, int, error) {
 	const errTemplate = "duplicate accelerator %q in rows %d and %d"
-	seen := make(map[rune]int) // key=char value=row in items
+	if assignment.seen == nil {
+		assignment.seen = make(map[rune]int, len(alphabet))
+	} else {
+		for c := range assignment.seen {
+			delete(assignment.seen, c)
+		}
+	}
+	seen := assignment.seen // key=char value=row in items
 	lines := make([]string, 0, len(items))
-	m := string(marker)
+	m := string(options.Marker)
 	mm := m + m
-	for row, column := range indexes {
-		if row == len(items) {
-			break
+	positions := resized(assignment.indexes, len(items)) // key=row
+	for row := range positions {
+		positions[row] = -1
+	}
+	for r, row := range assignment.rows {
+		if column := assignment.columns[row]; column > -1 {
+			positions[row] = assignment.positions[r][column]
 		}
+	}
+	assignment.indexes = positions
+	for row, column := range assignment.columns {
 		line := items[row]
 		if column == -1 || len(line) == 0 {
 			lines = append(lines, line)
 			continue // unassigned or empty
 		}
-		uline := strings.ReplaceAll(strings.ToUpper(line), mm, placeholder)
-		chars := []rune(uline)
-		i := slices.Index(chars, rune(marker))
-		if i > -1 && i+1 < len(chars) {
-			c := chars[i+1]
+		label := strings.ReplaceAll(withoutShortcut(line,
+			options.ShortcutSeparator), mm, placeholder)
+		if c := acceleratedChar(label, options.Marker); c != 0 {
+			c = options.upper(c)
 			if firstRow, found := seen[c]; found {
 				return nil, 0, fmt.Errorf(errTemplate, c, firstRow, row)
 			}
@@ -175,29 +687,64 @@ func applyIndexes(items []string, marker byte, alphabet []rune,
 			lines = append(lines, line)
 			continue // user preset
 		}
-		c := alphabet[column]
-		sc := string(c)
-		var index int      // first is best
-		if chars[0] != c { // !first
-			index = strings.Index(uline, " "+sc)
-			if index > -1 { // start of word is second best
-				index++ // skip the space
-			} else { // anywhere is third best
-				index = slices.Index(chars, c)
-			}
-		}
-		if index > -1 {
+		if index := positions[row]; index > -1 {
+			c := alphabet[assignment.keys[column]]
 			if firstRow, found := seen[c]; found {
 				return nil, 0, fmt.Errorf(errTemplate, c, firstRow, row)
 			}
 			seen[c] = row
-			line = line[:index] + m + line[index:]
+			offset := len(line)
+			for i := range line {
+				if index == 0 {
+					offset = i
+					break
+				}
+				index--
+			}
+			line = line[:offset] + m + line[offset:]
 		}
 		lines = append(lines, line)
 	}
-	for row := len(items) - (len(items) - len(indexes)); row < len(items); row++ {
-		lines = append(lines, items[row])
+	return lines, len(seen), nil
+}
+
+// Returns the slice with the given length, reusing its storage if it is
+// big enough (the contents are undefined).
+func resized[T any](slice []T, size int) []T {
+	if cap(slice) < size {
+		return make([]T, size)
+	}
+	return slice[:size]
+}
+
+// Returns the item without the shortcut separator and anything following
+// it (e.g., "Open\tCtrl+O" → "Open").
+func withoutShortcut(item, separator string) string {
+	if separator != "" {
+		if i := strings.Index(item, separator); i > -1 {
+			return item[:i]
+		}
 	}
+	return item
+}
 
-	return lines, len(seen), nil
+// Returns a slice the same length as the item's runes with true for each
+// rune that is inside a match of one of the placeholder patterns.
+// The excluded buffer is reused if it is big enough.
+func placeholderChars(excluded []bool, item string,
+	patterns []*regexp.Regexp) []bool {
+	excluded = resized(excluded, utf8.RuneCountInString(item))
+	for i := range excluded {
+		excluded[i] = false
+	}
+	for _, pattern := range patterns {
+		for _, span := range pattern.FindAllStringIndex(item, -1) {
+			start := utf8.RuneCountInString(item[:span[0]])
+			end := start + utf8.RuneCountInString(item[span[0]:span[1]])
+			for i := start; i < end; i++ {
+				excluded[i] = true
+			}
+		}
+	}
+	return excluded
 }
@@ -1,8 +1,12 @@
 package accelhint
 
 import (
+	"math"
+	"math/rand"
+	"regexp"
 	"strings"
 	"testing"
+	"unicode"
 
 	"golang.org/x/exp/slices"
 )
@@ -711,11 +715,11 @@ func Test004(t *testing.T) {
 			"&Edit",
 			"C&lipboard",
 			"&Promotion",
-			"N&otes",
+			"&Notes",
 			"&Calendar",
 			"Calendar &View",
 			"Calendar &Goto",
-			"&Note",
+			"N&ote",
 			"Cl&ipboard",
 			"E&dit",
 			"&Search",
@@ -751,19 +755,19 @@ func Test004(t *testing.T) {
 			"T&ype",
 			"&Title",
 			"&Forenames",
-			"Surname",
+			"&Surname",
 			"&Company",
 			"&Email",
 			"&Phone",
 			"&Mobile",
-			"&URL",
+			"URL",
 			"F&ind",
 			"&New",
 			"&Duplicate",
-			"Upd&ate",
+			"&Update",
 			"De&lete",
 			"Confi&gure",
-			"&Statistics",
+			"St&atistics",
 			"E&xport",
 			"&Open",
 			"Sa&ve",
@@ -800,7 +804,7 @@ func Test004(t *testing.T) {
 			"P&ython Documentation",
 		},
 		{ // 13
-			"Undo",
+			"Un&do",
 			"&Redo",
 			"&Copy",
 			"Cu&t",
@@ -813,7 +817,7 @@ func Test004(t *testing.T) {
 			"&Goto Line",
 			"Goto &Matching",
 			"&Indent Region",
-			"Unin&dent Region",
+			"Unindent Region",
 			"C&omment Region",
 			"&Uncomment Region",
 		},
@@ -866,8 +870,8 @@ func Test004(t *testing.T) {
 			"&Templates",
 			"Preview in Web &Browser",
 			"Pa&ge Preview",
-			"Pr&int...",
-			"&Printer Settings...",
+			"&Print...",
+			"Pr&inter Settings...",
 			"E&xit",
 		},
 		{ // 17
@@ -880,19 +884,19 @@ func Test004(t *testing.T) {
 			"Select &Text",
 			"&Select All",
 			"&Changes",
-			"Co&mpare Documents...",
+			"Compare &Documents...",
 			"Find and Replace",
 			"&Navigator",
 			"&AutoText...",
 			"&Exchange Database...",
-			"Fiel&ds...",
+			"Fields...",
 			"&Footnote...",
-			"Inde&x Entry...",
+			"&Index Entry...",
 			"&Bibliography Entry",
 			"&Hyperlink",
 			"&Links...",
 			"Plu&g-in",
-			"&ImageMap",
+			"I&mageMap",
 			"&Object",
 		},
 		{ // 18
@@ -1008,19 +1012,19 @@ func Test004(t *testing.T) {
 			"Select Te&xt",
 			"&Select All",
 			"Changes",
-			"Co&mpare Documents...",
+			"Compare &Documents...",
 			"Find and Replace",
 			"&Navigator",
 			"&AutoText...",
 			"&Exchange Database...",
-			"Fiel&ds...",
+			"Fields...",
 			"&Footnote...",
-			"Index Entr&y...",
+			"&Index Entry...",
 			"&Bibliography Entry",
 			"&Hyperlink",
 			"&Links...",
 			"Plu&g-in",
-			"&ImageMap",
+			"I&mageMap",
 			"&Object",
 		},
 		{ // 24
@@ -1054,20 +1058,20 @@ func Test004(t *testing.T) {
 			"Cu&t",
 			"&Copy",
 			"Paste",
-			"Paste Special...",
+			"&Paste Special...",
 			"Select All",
-			"Chan&ges",
+			"Changes",
 			"Co&mpare Document...",
 			"Find &and Replace...",
 			"&Navigator",
 			"&Headers and Footers...",
 			"&Fill",
-			"D&elete Contents...",
-			"&Delete Cells...",
+			"&Delete Contents...",
+			"D&elete Cells...",
 			"&Sheet",
 			"Delete Manual &Break",
 			"&Links...",
-			"&Plug-in",
+			"Plu&g-in",
 			"&ImageMap",
 			"&Object",
 		},
@@ -1078,25 +1082,25 @@ func Test004(t *testing.T) {
 			"Cu&t",
 			"&Copy",
 			"Paste",
-			"Paste Special...",
+			"&Paste Special...",
 			"Select All",
-			"Chan&ges",
+			"Changes",
 			"Co&mpare Document...",
 			"Find &and Replace...",
 			"&Navigator",
 			"&Headers and Footers...",
 			"&Fill",
-			"D&elete Contents...",
-			"&Delete Cells...",
+			"&Delete Contents...",
+			"D&elete Cells...",
 			"&Sheet",
 			"Delete Manual &Break",
 			"&Links...",
-			"&Plug-in",
+			"Plu&g-in",
 			"&ImageMap",
 			"&Object",
 		},
 		{ // 27
-			"Undo",
+			"Un&do",
 			"&Redo",
 			"&Copy",
 			"Cu&t",
@@ -1109,7 +1113,7 @@ func Test004(t *testing.T) {
 			"&Goto Line",
 			"Goto &Matching",
 			"&Indent Region",
-			"Unin&dent Region",
+			"Unindent Region",
 			"C&omment Region",
 			"&Uncomment Region",
 		},
@@ -1229,23 +1233,23 @@ func TestBad2(t *testing.T) {
 		"Find Again",
 		"Find && Replace",
 		"Undo",
-		"Redo",
+		"&Redo",
 		"Copy",
 		"Cut",
 		"&Paste",
-		"Fi&nd",
+		"Find",
 		"Find &Again",
-		"Find && Rep&lace",
+		"&Find && Replace",
 		"&Undo",
-		"&Redo",
+		"R&edo",
 		"C&opy",
 		"&Cut",
 		"Pa&ste",
 		"F&ind",
 		"Find A&gain",
-		"&Find && Replace",
-		"Un&do",
-		"R&edo",
+		"Find && Rep&lace",
+		"U&ndo",
+		"Re&do",
 		"Cop&y",
 		"CUT",
 		"PASTE",
@@ -1263,14 +1267,133 @@ func TestBad2(t *testing.T) {
 			t.Errorf("expected %q, got %q", expected[i], hinted[i])
 		}
 	}
-	expectedAccels := []rune{'t', 'P', 'n', 'A', 'l', 'U', 'R', 'o', 'C',
-		's', 'i', 'g', 'F', 'd', 'e', 'y'}
+	expectedAccels := []rune{'t', 'R', 'P', 'A', 'F', 'U', 'e', 'o', 'C',
+		's', 'i', 'g', 'l', 'n', 'd', 'y'}
 	accels := drop(Accelerators(hinted), 0)
 	if !slices.Equal(accels, expectedAccels) {
 		t.Errorf("expected\n%+v accels, got\n%+v", expectedAccels, accels)
 	}
 }
 
+func TestShortcuts(t *testing.T) {
+	original := []string{"Open\tCtrl+O", "Close\tCtrl+W", "Copy\tCtrl+C",
+		"Cut\tCtrl+X", "Save &As...\tCtrl+Shift+S"}
+	expected := []string{"&Open\tCtrl+O", "C&lose\tCtrl+W",
+		"&Copy\tCtrl+C", "C&ut\tCtrl+X", "Save &As...\tCtrl+Shift+S"}
+	hinted, count, err := Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if count != 5 {
+		t.Errorf("expected 5 accelerated got %d", count)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	options := NewOptions()
+	options.ShortcutSeparator = " | "
+	hinted, _, err = options.Hinted([]string{"Open | O", "Oops | P"})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected = []string{"O&pen | O", "&Oops | P"}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	original := []string{"Save", "%s Settings", "Open {name}",
+		"Close %1", "Run $(file)", "New"}
+	options := NewOptions()
+	options.Placeholders = []*regexp.Regexp{PrintfPlaceholders,
+		QtPlaceholders, BracePlaceholders, ShellPlaceholders}
+	expected := []string{"&Save", "%s S&ettings", "&Open {name}",
+		"&Close %1", "&Run $(file)", "&New"}
+	hinted, _, err := options.Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	hinted, _, err = options.Hinted([]string{"%s", "{n} %d", "Open",
+		"Only"})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected = []string{"%s", "{n} %d", "&Open", "O&nly"}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+}
+
+// Checks the property that each marker is inserted at the position that
+// was scored, i.e., before the best occurrence of its letter: the first
+// char, else the first word start, else the first occurrence.
+func TestPlacement(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	const chars = "abcABC  \t-_/&.éÉ"
+	wordStarts := []WordStartsFunc{SpaceWordStarts, PunctuationWordStarts,
+		CamelCaseWordStarts, UAX29WordStarts}
+	for n := 0; n < 500; n++ {
+		options := NewOptions()
+		options.Alphabet = "ABCÉ"
+		options.WordStarts = wordStarts[n%len(wordStarts)]
+		items := make([]string, 1+random.Intn(6))
+		for i := range items {
+			item := make([]rune, 1+random.Intn(12))
+			for j := range item {
+				item[j] = []rune(chars)[random.Intn(len([]rune(chars)))]
+			}
+			items[i] = string(item)
+		}
+		hinted, _, err := options.Hinted(items)
+		if err != nil {
+			continue // e.g., random duplicate presets
+		}
+		for row, line := range hinted {
+			if line == items[row] {
+				continue // preset or unassigned
+			}
+			accel := unicode.ToUpper(Accelerators([]string{line})[0])
+			index := bestIndex(items[row], accel, options)
+			chars := []rune(items[row])
+			expected := string(chars[:index]) + "&" + string(chars[index:])
+			if line != expected {
+				t.Errorf("%q: expected %q, got %q", items[row], expected,
+					line)
+			}
+		}
+	}
+}
+
+func bestIndex(item string, c rune, options *Options) int {
+	chars := []rune(strings.ReplaceAll(withoutShortcut(item,
+		options.ShortcutSeparator), "&&", placeholder))
+	starts := options.WordStarts(nil, chars)
+	wordStart := -1
+	for i, x := range chars {
+		if unicode.ToUpper(x) == c {
+			if i == 0 {
+				return i
+			}
+			if starts[i] && wordStart == -1 {
+				wordStart = i
+			}
+		}
+	}
+	if wordStart > -1 {
+		return wordStart
+	}
+	for i, x := range chars {
+		if unicode.ToUpper(x) == c {
+			return i
+		}
+	}
+	return -1
+}
+
 func sanityCheck(hinted []string, t *testing.T) {
 	used := make(map[rune]bool, len(hinted))
 	for _, hints := range hinted {
@@ -1297,3 +1420,291 @@ func drop[T comparable](items []T, x T) []T {
 	}
 	return result
 }
+
+func TestRectangular(t *testing.T) {
+	items := []string{"One", "Two", "-", "Ox"}
+	assignment, err := assign(items, NewOptions(), []rune(Alphabet))
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if len(assignment.weights) != 3 {
+		t.Errorf("expected 3 rows, got %d", len(assignment.weights))
+	}
+	keys := make([]rune, 0, len(assignment.keys))
+	for _, j := range assignment.keys {
+		keys = append(keys, []rune(Alphabet)[j])
+	}
+	if string(keys) != "ENOTWX" {
+		t.Errorf("expected keys \"ENOTWX\", got %q", string(keys))
+	}
+	for _, row := range assignment.weights {
+		if len(row) != len(keys) {
+			t.Errorf("expected %d columns, got %d", len(keys), len(row))
+		}
+	}
+	hinted, count, err := Hinted(items)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected := []string{"&One", "&Two", "-", "O&x"}
+	if count != 3 || !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q (%d)", expected, hinted, count)
+	}
+}
+
+func TestTies(t *testing.T) {
+	// Swapping chars whose claims differ by the same amount gives equally
+	// good assignments: the earlier item gets its better char, whichever
+	// optimal Assigner is used
+	originals := [][]string{
+		{"Save", "Save-As"},
+		{"Zoom In", "Zoom Out"},
+		{"One", "Two", "Ox"},
+		{"Undo", "Redo", "Undo", "Redo"},
+		{"Close", "Clone", "Clear"},
+		{"AB", "BA", "AB", "BA"},
+	}
+	expecteds := [][]string{
+		{"&Save", "S&ave-As"},
+		{"&Zoom In", "Zoom &Out"},
+		{"&One", "&Two", "O&x"},
+		{"&Undo", "&Redo", "U&ndo", "R&edo"},
+		{"&Close", "C&lone", "Cl&ear"},
+		{"AB", "BA", "&AB", "&BA"}, // later rows win when items compete
+	}
+	for i, original := range originals {
+		for _, assigner := range []Assigner{Hungarian, JonkerVolgenant,
+			NewJonkerVolgenant()} {
+			options := NewOptions()
+			options.Assigner = assigner
+			for j := 0; j < 3; j++ {
+				hinted, _, err := options.Hinted(original)
+				if err != nil {
+					t.Errorf("#%d unexpected error: %s", i, err)
+				}
+				if !slices.Equal(hinted, expecteds[i]) {
+					t.Errorf("#%d %T expected %q, got %q", i, assigner,
+						expecteds[i], hinted)
+				}
+			}
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	// Each optimal Assigner's result (with few distinct costs, so lots of
+	// ties) must become the optimal assignment where each row in turn has
+	// the best column it can
+	random := rand.New(rand.NewSource(1))
+	for n := 0; n < 500; n++ {
+		rows := 1 + random.Intn(6)
+		columns := rows + random.Intn(3)
+		weights := make(weights, rows)
+		for row := range weights {
+			weights[row] = make([]float64, columns)
+			for column := range weights[row] {
+				weights[row][column] = float64(random.Intn(3))
+				if random.Intn(4) == 0 {
+					weights[row][column] = maxWeight
+				}
+			}
+		}
+		expected := bestAssignment(weights)
+		for _, assigner := range []Assigner{Hungarian, JonkerVolgenant} {
+			assignment := &assignment{weights: weights,
+				indexes: make([]int, rows)}
+			if err := assigner.Assign(weights, assignment.indexes); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			assignment.canonicalize()
+			if !slices.Equal(assignment.indexes, expected) {
+				t.Errorf("%T expected %v, got %v for %v", assigner, expected,
+					assignment.indexes, weights)
+			}
+		}
+	}
+}
+
+// Returns the optimal assignment where each row in turn has the lowest
+// weight (and then column) it can, found by trying every assignment.
+func bestAssignment(weights weights) []int {
+	var best []int
+	bestTotal := 0.0
+	current := make([]int, len(weights))
+	used := make([]bool, len(weights[0]))
+	better := func() bool {
+		for row, column := range current {
+			weight, bestWeight := weights[row][column], weights[row][best[row]]
+			if weight != bestWeight {
+				return weight < bestWeight
+			}
+			if column != best[row] {
+				return column < best[row]
+			}
+		}
+		return false
+	}
+	var try func(row int, total float64)
+	try = func(row int, total float64) {
+		if row == len(weights) {
+			if best == nil || total < bestTotal ||
+				(total == bestTotal && better()) {
+				best = slices.Clone(current)
+				bestTotal = total
+			}
+			return
+		}
+		for column, weight := range weights[row] {
+			if !used[column] {
+				used[column] = true
+				current[row] = column
+				try(row+1, total+weight)
+				used[column] = false
+			}
+		}
+	}
+	try(0, 0)
+	return best
+}
+
+func TestWholeWeights(t *testing.T) {
+	options := NewOptions()
+	options.Importance = map[string]int{"Find Again": 3, "Copy": -2}
+	options.Preferred = map[string]string{"Paste": "ST"}
+	items := []string{"Undo", "Redo", "Copy", "Cu&t", "Paste", "Find",
+		"Find Again", "Find && Replace"}
+	assignment, err := assign(items, options, []rune(Alphabet))
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	for r, row := range assignment.weights {
+		for c, weight := range row {
+			if weight != math.Trunc(weight) {
+				t.Errorf("weight %d,%d isn't whole: %f", r, c, weight)
+			}
+		}
+	}
+}
+
+func TestRuneColumns(t *testing.T) {
+	// The 'a's are both at index 2 so the later row is preferred; if bytes
+	// were counted "ééa"'s would be at 4 and lose
+	hinted, _, err := HintedX([]string{"xxa", "ééa"}, Marker, "AZ")
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected := []string{"xxa", "éé&a"}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	hinted, _, err = HintedX([]string{"ééa", "xxxxa"}, Marker, "AZ")
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected = []string{"éé&a", "xxxxa"}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+}
+
+func TestGraphemes(t *testing.T) {
+	originals := [][]string{
+		{"e\u0301a"},
+		{"1\ufe0f\u20e3 One"}, // keycap
+		{"👩‍💻 Code"},
+		{"कि"}, // KA + vowel sign I
+	}
+	alphabets := []string{Alphabet, Alphabet, Alphabet, "कि"}
+	expecteds := [][]string{
+		{"e\u0301&a"},
+		{"1\ufe0f\u20e3 &One"},
+		{"👩‍💻 &Code"},
+		{"कि"},
+	}
+	baseExpecteds := [][]string{
+		{"&e\u0301a"},
+		{"&1\ufe0f\u20e3 One"},
+		{"👩‍💻 &Code"},
+		{"&कि"},
+	}
+	for i, original := range originals {
+		for _, baseLetters := range []bool{false, true} {
+			options := NewOptions()
+			options.Alphabet = alphabets[i]
+			options.BaseLetters = baseLetters
+			expected := expecteds[i]
+			if baseLetters {
+				expected = baseExpecteds[i]
+			}
+			hinted, _, err := options.Hinted(original)
+			if err != nil {
+				t.Errorf("#%d unexpected error: %s", i, err)
+			}
+			if !slices.Equal(hinted, expected) {
+				t.Errorf("#%d %t expected %q, got %q", i, baseLetters,
+					expected, hinted)
+			}
+		}
+	}
+}
+
+func TestRightToLeft(t *testing.T) {
+	const rlm, rli, pdi, alm = "\u200f", "\u2067", "\u2069", "\u061c"
+	options := NewOptions()
+	options.Alphabet = "אבגדהוזחטיכלמנסעפצקרשת"
+	original := []string{rlm + "קובץ", rli + "עריכה" + pdi, "תצוגה",
+		rlm + "עזרה", "שמור", "שמור " + rlm + "בשם"}
+	expected := []string{rlm + "&קובץ", rli + "&עריכה" + pdi, "&תצוגה",
+		rlm + "ע&זרה", "&שמור", "שמור " + rlm + "&בשם"}
+	hinted, _, err := options.Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	score := Quality(hinted, Marker)
+	if score.First != 4 || score.WordStart != 1 || score.MidWord != 1 {
+		t.Errorf("unexpected score %s", score)
+	}
+	options.Alphabet = "ابتثجحخدذرزسشصضطظعغفقكلمنهوي"
+	original = []string{alm + "ملف", "تحرير", rlm + "عرض", "حفظ",
+		"حفظ " + alm + "باسم"}
+	expected = []string{alm + "&ملف", "&تحرير", rlm + "&عرض", "&حفظ",
+		"حفظ " + alm + "&باسم"}
+	hinted, _, err = options.Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+}
+
+func TestRightToLeftPreset(t *testing.T) {
+	// A marker before a bidi mark accelerates the char after the mark
+	const rlm = "\u200f"
+	original := []string{"&" + rlm + "X", "Xy", "&" + rlm}
+	expected := []string{"&" + rlm + "X", "X&y", "&" + rlm}
+	hinted, count, err := Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if count != 2 || !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q (%d)", expected, hinted, count)
+	}
+	accels := Accelerators(hinted)
+	if expected := []rune{'X', 'y', 0}; !slices.Equal(accels, expected) {
+		t.Errorf("expected %q, got %q", expected, accels)
+	}
+	diagnostics := Check(hinted, Marker)
+	if len(diagnostics) != 1 || diagnostics[0].Kind != DanglingMarker ||
+		diagnostics[0].Row != 2 {
+		t.Errorf("expected only a dangling marker in row 2, got %v",
+			diagnostics)
+	}
+	_, _, err = Hinted([]string{"&" + rlm + "X", "&Xy"})
+	if err == nil {
+		t.Error("expected a duplicate accelerator error")
+	}
+}
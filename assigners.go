@@ -0,0 +1,244 @@
+// Copyright © 2023 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package accelhint
+
+import (
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/charles-haynes/munkres"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	ErrIrregularCosts = errors.New("cost matrix rows differ in length")
+	ErrNonFiniteCost  = errors.New("cost matrix has an infinite or NaN cost")
+)
+
+// An Assigner solves the assignment problem for a rectangular cost matrix
+// (a row per item and a column per candidate character), setting
+// assignment[row] to the column assigned to each row, or to -1 if the row
+// has none (which only happens if there are more rows than columns).
+// Each column is assigned to at most one row.
+type Assigner interface {
+	Assign(costs [][]float64, assignment []int) error
+}
+
+var (
+	// Finds an optimal assignment using github.com/charles-haynes/munkres'
+	// Hungarian algorithm. This is the default.
+	Hungarian Assigner = hungarian{}
+
+	// Finds an optimal assignment using the shortest augmenting path
+	// algorithm of Jonker and Volgenant (as adapted for rectangular
+	// matrices by Crouse) without any external dependency.
+	JonkerVolgenant Assigner = jonkerVolgenant{}
+
+	// Finds a good but not necessarily optimal assignment quickly by
+	// repeatedly assigning the cheapest remaining row and column pair.
+	Greedy Assigner = greedy{}
+)
+
+type hungarian struct{}
+
+func (hungarian) Assign(costs [][]float64, assignment []int) error {
+	m, err := munkres.NewHungarianAlgorithm(costs)
+	if err != nil {
+		return err
+	}
+	copy(assignment, m.Execute())
+	return nil
+}
+
+// Returns an Assigner that works like JonkerVolgenant but which reuses its
+// buffers from one call to the next, keeping them in a pool so that it is
+// safe for concurrent use. (A Hinter needn't use this since it solves
+// with buffers of its own for either.)
+func NewJonkerVolgenant() Assigner {
+	return &jonkerVolgenant{pool: &sync.Pool{}}
+}
+
+type jonkerVolgenant struct {
+	pool *sync.Pool // of *jvBuffers; nil means allocate afresh each call
+}
+
+// jvBuffers holds the working storage for shortestAugmentingPaths.
+type jvBuffers struct {
+	u, v, minV       []float64
+	rowFor, way, row []int
+	used             []bool
+}
+
+func (jv jonkerVolgenant) Assign(costs [][]float64, assignment []int) error {
+	if jv.pool == nil {
+		return (&jvBuffers{}).assign(costs, assignment)
+	}
+	buffers, ok := jv.pool.Get().(*jvBuffers)
+	if !ok {
+		buffers = &jvBuffers{}
+	}
+	defer jv.pool.Put(buffers)
+	return buffers.assign(costs, assignment)
+}
+
+// Solves the assignment problem as JonkerVolgenant does, reusing the
+// buffers.
+func (buffers *jvBuffers) assign(costs [][]float64, assignment []int) error {
+	rows, columns, err := checkCosts(costs)
+	if err != nil || rows == 0 {
+		return err
+	}
+	if rows <= columns {
+		buffers.shortestAugmentingPaths(rows, columns, costs, false,
+			assignment)
+		return nil
+	}
+	// More rows than columns so solve the transpose
+	buffers.row = resized(buffers.row, columns)
+	transposed := buffers.row
+	buffers.shortestAugmentingPaths(columns, rows, costs, true, transposed)
+	for row := range assignment[:rows] {
+		assignment[row] = -1
+	}
+	for column, row := range transposed {
+		assignment[row] = column
+	}
+	return nil
+}
+
+// Sets assignment[row] for rows ≤ columns using shortest augmenting paths
+// with row and column potentials (u and v), one row at a time. If
+// transposed the costs are indexed by column then row.
+func (buffers *jvBuffers) shortestAugmentingPaths(rows, columns int,
+	costs [][]float64, transposed bool, assignment []int) {
+	cost := func(row, column int) float64 {
+		if transposed {
+			return costs[column][row]
+		}
+		return costs[row][column]
+	}
+	// Index 0 is a sentinel, so row r and column c are at r+1 and c+1
+	buffers.u = zeroed(buffers.u, rows+1)
+	buffers.v = zeroed(buffers.v, columns+1)
+	buffers.rowFor = zeroed(buffers.rowFor, columns+1) // 0 is unassigned
+	buffers.way = zeroed(buffers.way, columns+1)
+	buffers.minV = zeroed(buffers.minV, columns+1)
+	buffers.used = zeroed(buffers.used, columns+1)
+	u, v, rowFor, way := buffers.u, buffers.v, buffers.rowFor, buffers.way
+	minV, used := buffers.minV, buffers.used
+	for row := 1; row <= rows; row++ {
+		rowFor[0] = row
+		column0 := 0
+		for j := range minV {
+			minV[j] = math.Inf(1)
+			used[j] = false
+		}
+		for {
+			used[column0] = true
+			row0 := rowFor[column0]
+			delta := math.Inf(1)
+			column1 := 0
+			for column := 1; column <= columns; column++ {
+				if !used[column] {
+					reduced := cost(row0-1, column-1) - u[row0] - v[column]
+					if reduced < minV[column] {
+						minV[column] = reduced
+						way[column] = column0
+					}
+					if minV[column] < delta {
+						delta = minV[column]
+						column1 = column
+					}
+				}
+			}
+			for column := 0; column <= columns; column++ {
+				if used[column] {
+					u[rowFor[column]] += delta
+					v[column] -= delta
+				} else {
+					minV[column] -= delta
+				}
+			}
+			column0 = column1
+			if rowFor[column0] == 0 {
+				break
+			}
+		}
+		for column0 != 0 { // augment along the path
+			column1 := way[column0]
+			rowFor[column0] = rowFor[column1]
+			column0 = column1
+		}
+	}
+	for row := range assignment[:rows] {
+		assignment[row] = -1
+	}
+	for column := 1; column <= columns; column++ {
+		if rowFor[column] != 0 {
+			assignment[rowFor[column]-1] = column - 1
+		}
+	}
+}
+
+type greedy struct{}
+
+func (greedy) Assign(costs [][]float64, assignment []int) error {
+	rows, columns, err := checkCosts(costs)
+	if err != nil {
+		return err
+	}
+	type pair struct{ row, column int }
+	pairs := make([]pair, 0, rows*columns)
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			pairs = append(pairs, pair{row, column})
+		}
+	}
+	slices.SortStableFunc(pairs, func(a, b pair) bool {
+		return costs[a.row][a.column] < costs[b.row][b.column]
+	})
+	for row := range assignment[:rows] {
+		assignment[row] = -1
+	}
+	taken := make([]bool, columns)
+	for _, pair := range pairs {
+		if assignment[pair.row] == -1 && !taken[pair.column] {
+			assignment[pair.row] = pair.column
+			taken[pair.column] = true
+		}
+	}
+	return nil
+}
+
+// Returns the slice with the given length and every value zero, reusing its
+// storage if it is big enough.
+func zeroed[T any](slice []T, size int) []T {
+	slice = resized(slice, size)
+	var zero T
+	for i := range slice {
+		slice[i] = zero
+	}
+	return slice
+}
+
+// Returns the number of rows and columns if costs is rectangular and
+// finite.
+func checkCosts(costs [][]float64) (int, int, error) {
+	if len(costs) == 0 {
+		return 0, 0, nil
+	}
+	columns := len(costs[0])
+	for _, row := range costs {
+		if len(row) != columns {
+			return 0, 0, ErrIrregularCosts
+		}
+		for _, cost := range row {
+			if math.IsInf(cost, 0) || math.IsNaN(cost) {
+				return 0, 0, ErrNonFiniteCost
+			}
+		}
+	}
+	return len(costs), columns, nil
+}
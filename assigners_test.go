@@ -0,0 +1,141 @@
+package accelhint
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestAssigners(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	reused := NewJonkerVolgenant() // shared to exercise buffer reuse
+	for n := 0; n < 200; n++ {
+		rows, columns := 1+random.Intn(12), 1+random.Intn(12)
+		costs := make([][]float64, rows)
+		for row := range costs {
+			costs[row] = make([]float64, columns)
+			for column := range costs[row] {
+				costs[row][column] = float64(random.Intn(20))
+			}
+		}
+		var best float64
+		for i, assigner := range []Assigner{Hungarian, JonkerVolgenant,
+			reused, Greedy} {
+			assignment := make([]int, rows)
+			if err := assigner.Assign(costs, assignment); err != nil {
+				t.Fatalf("#%d: unexpected error: %s", i, err)
+			}
+			total := checkAssignment(t, costs, assignment)
+			switch {
+			case i == 0:
+				best = total
+			case assigner == Greedy:
+				if total < best {
+					t.Errorf("greedy %v beat optimal %v", total, best)
+				}
+			case total != best:
+				t.Errorf("%dx%d: expected total %v, got %v for %v", rows,
+					columns, best, total, costs)
+			}
+		}
+	}
+}
+
+func TestJonkerVolgenantConcurrent(t *testing.T) {
+	// Its buffers are pooled, so it can be shared (run this with -race)
+	assigner := NewJonkerVolgenant()
+	var wg sync.WaitGroup
+	for worker := 0; worker < 4; worker++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			random := rand.New(rand.NewSource(seed))
+			for n := 0; n < 50; n++ {
+				size := 1 + random.Intn(12)
+				costs := make([][]float64, size)
+				for row := range costs {
+					costs[row] = make([]float64, size)
+					for column := range costs[row] {
+						costs[row][column] = float64(random.Intn(20))
+					}
+				}
+				expected, assignment := make([]int, size), make([]int, size)
+				_ = JonkerVolgenant.Assign(costs, expected)
+				if err := assigner.Assign(costs, assignment); err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				if !slices.Equal(assignment, expected) {
+					t.Errorf("expected %v, got %v", expected, assignment)
+				}
+			}
+		}(int64(worker))
+	}
+	wg.Wait()
+}
+
+func TestAssignersBad(t *testing.T) {
+	for _, assigner := range []Assigner{JonkerVolgenant, Greedy} {
+		err := assigner.Assign([][]float64{{1, 2}, {3}}, make([]int, 2))
+		if err != ErrIrregularCosts {
+			t.Errorf("expected ErrIrregularCosts, got %v", err)
+		}
+		err = assigner.Assign([][]float64{{1, math.Inf(1)}}, make([]int, 1))
+		if err != ErrNonFiniteCost {
+			t.Errorf("expected ErrNonFiniteCost, got %v", err)
+		}
+	}
+}
+
+func TestAssignerOption(t *testing.T) {
+	original := []string{"Undo", "Redo", "Copy", "Cu&t", "Paste", "Find",
+		"Find Again", "Find && Replace"}
+	expected := []string{"&Undo", "&Redo", "&Copy", "Cu&t", "&Paste",
+		"&Find", "Find &Again", "F&ind && Replace"}
+	options := NewOptions()
+	for i, test := range []struct {
+		assigner Assigner
+		expected []string
+	}{
+		{Hungarian, expected},
+		{JonkerVolgenant, expected},
+		{Greedy, expected}, // optimal here, so its ties are broken too
+	} {
+		options.Assigner = test.assigner
+		hinted, _, err := options.Hinted(original)
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %s", i, err)
+		}
+		if !slices.Equal(hinted, test.expected) {
+			t.Errorf("#%d: expected %q, got %q", i, test.expected, hinted)
+		}
+	}
+}
+
+// Returns the total cost after checking that each row has a column (if
+// there are enough) and that no column is used more than once.
+func checkAssignment(t *testing.T, costs [][]float64,
+	assignment []int) float64 {
+	total := 0.0
+	used := make(map[int]bool)
+	unassigned := 0
+	for row, column := range assignment {
+		if column == -1 {
+			unassigned++
+			continue
+		}
+		if used[column] {
+			t.Errorf("column %d assigned more than once in %v", column,
+				assignment)
+		}
+		used[column] = true
+		total += costs[row][column]
+	}
+	if expected := len(costs) - len(costs[0]); unassigned != expected &&
+		(expected > 0 || unassigned != 0) {
+		t.Errorf("expected %d unassigned rows, got %v", expected, assignment)
+	}
+	return total
+}
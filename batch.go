@@ -0,0 +1,115 @@
+// Copyright © 2023 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package accelhint
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// BatchResult holds the result of hinting one of a batch's lists of items.
+// Err is set if the list couldn't be hinted (e.g., because of duplicate
+// presets), or wasn't because the batch was cancelled.
+type BatchResult struct {
+	Hinted []string
+	Count  int
+	Err    error
+}
+
+// Returns items with '&'s to indicate accelerators, and the number
+// accelerated, exactly as Hinted does, unless the context is done first, in
+// which case the context's error is returned.
+// See also Options.HintedContext.
+func HintedContext(ctx context.Context, items []string) ([]string, int,
+	error) {
+	return NewOptions().HintedContext(ctx, items)
+}
+
+// Returns items with markers to indicate accelerators, and the number
+// accelerated, exactly as the Options' Hinted method does, unless the
+// context is done first, in which case the context's error is returned.
+// The context is checked before each item is weighed and before the
+// weights are solved; the Assigner can't be interrupted, but if the context
+// is done while it is solving, the context's error is still returned.
+// See also HintedContext.
+func (options *Options) HintedContext(ctx context.Context, items []string) (
+	[]string, int, error) {
+	return hintedContext(ctx, NewHinter(options), items)
+}
+
+// Returns a result for each list of items in the batch (in the same order)
+// hinted one after another using the Options. If the context is done first,
+// the lists not yet hinted have the context's error as their Err, and the
+// context's error is returned.
+// See also HintedBatchParallel.
+func (options *Options) HintedBatch(ctx context.Context, batch [][]string) (
+	[]BatchResult, error) {
+	return options.HintedBatchParallel(ctx, batch, 1)
+}
+
+// Returns a result for each list of items in the batch (in the same order)
+// hinted by the given number of workers in parallel (or by
+// runtime.GOMAXPROCS(0) workers if workers is 0 or less) using the Options.
+// The workers share the Options' Assigner, so it must be safe for
+// concurrent use, as all the package's Assigners are. If the context is
+// done first, the lists not yet hinted have the context's error as their
+// Err, and the context's error is returned.
+// See also HintedBatch.
+func (options *Options) HintedBatchParallel(ctx context.Context,
+	batch [][]string, workers int) ([]BatchResult, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(batch) {
+		workers = len(batch)
+	}
+	hinter := NewHinter(options)
+	results := make([]BatchResult, len(batch))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result := &results[i]
+				result.Hinted, result.Count, result.Err = hintedContext(ctx,
+					hinter, batch[i])
+			}
+		}()
+	}
+	for i := 0; i < len(batch) && ctx.Err() == nil; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		for i := range results {
+			if results[i].Hinted == nil && results[i].Err == nil {
+				results[i].Err = err
+			}
+		}
+		return results, err
+	}
+	return results, nil
+}
+
+func hintedContext(ctx context.Context, hinter *Hinter, items []string) (
+	[]string, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	hinted, count, err := hinter.hintedContext(ctx, items)
+	if err == nil {
+		err = ctx.Err() // e.g., the deadline passed while solving
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return hinted, count, nil
+}
@@ -0,0 +1,153 @@
+package accelhint
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestHintedContext(t *testing.T) {
+	expected, _, _ := Hinted(fileMenu)
+	hinted, count, err := HintedContext(context.Background(), fileMenu)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if count != 14 || !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q (%d)", expected, hinted, count)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err = HintedContext(ctx, fileMenu); !errors.Is(err,
+		context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestHintedContextWeighing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	weighed := 0
+	options := NewOptions()
+	options.WordStarts = func(starts []bool, chars []rune) []bool {
+		weighed++
+		cancel() // while weighing the first item
+		return SpaceWordStarts(starts, chars)
+	}
+	if _, _, err := options.HintedContext(ctx, bigMenu(30)); !errors.Is(err,
+		context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if weighed != 1 {
+		t.Errorf("expected 1 item weighed, got %d", weighed)
+	}
+	hinter := NewCachedHinter(nil, 2) // cancelled results aren't cached
+	if _, _, err := hinter.hintedContext(ctx, fileMenu); !errors.Is(err,
+		context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if hinter.Len() != 0 {
+		t.Errorf("expected 0 cached, got %d", hinter.Len())
+	}
+}
+
+func TestHintedBatch(t *testing.T) {
+	batch := [][]string{contextMenu, fileMenu, {"&Cat", "&Cow"}, editMenu,
+		{}, bigMenu(40)}
+	for workers := -1; workers < 4; workers++ {
+		var results []BatchResult
+		var err error
+		if workers == -1 {
+			results, err = NewOptions().HintedBatch(context.Background(),
+				batch)
+		} else {
+			results, err = NewOptions().HintedBatchParallel(
+				context.Background(), batch, workers)
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if len(results) != len(batch) {
+			t.Fatalf("expected %d results, got %d", len(batch),
+				len(results))
+		}
+		for i, items := range batch {
+			expected, count, err := Hinted(items)
+			result := results[i]
+			if (err == nil) != (result.Err == nil) {
+				t.Errorf("%d/#%d expected error %v, got %v", workers, i, err,
+					result.Err)
+			}
+			if result.Count != count || !slices.Equal(result.Hinted,
+				expected) {
+				t.Errorf("%d/#%d expected %q, got %q", workers, i, expected,
+					result.Hinted)
+			}
+		}
+	}
+}
+
+func TestHintedBatchAssigners(t *testing.T) {
+	// The workers share the Options' Assigner, so run this with -race too
+	batch := make([][]string, 0, 40)
+	for i := 0; i < cap(batch); i++ {
+		batch = append(batch, bigMenu(20+i%20))
+	}
+	for _, assigner := range []Assigner{NewJonkerVolgenant(),
+		JonkerVolgenant, Hungarian} {
+		options := NewOptions()
+		options.Assigner = assigner
+		results, err := options.HintedBatchParallel(context.Background(),
+			batch, 4)
+		if err != nil {
+			t.Errorf("%T unexpected error: %s", assigner, err)
+		}
+		for i, items := range batch {
+			expected, _, _ := Hinted(items) // the same for any optimal one
+			if !slices.Equal(results[i].Hinted, expected) {
+				t.Errorf("%T #%d expected %q, got %q", assigner, i,
+					expected, results[i].Hinted)
+			}
+		}
+	}
+}
+
+func TestHintedBatchCancel(t *testing.T) {
+	batch := make([][]string, 0, 100)
+	for i := 0; i < cap(batch); i++ {
+		batch = append(batch, bigMenu(20+i%20))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := NewOptions().HintedBatchParallel(ctx, batch, 4)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	for i, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("#%d expected context.Canceled, got %v", i, result.Err)
+		}
+	}
+	options := NewOptions()
+	ctx, cancel = context.WithCancel(context.Background())
+	done := 0
+	options.Skip = func(row int, item string) bool {
+		if row == 0 {
+			done++
+			if done == 3 {
+				cancel() // while hinting the third list
+			}
+		}
+		return false
+	}
+	results, err = options.HintedBatch(ctx, batch)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	for i, result := range results {
+		if (i < 2) != (result.Err == nil) {
+			t.Errorf("#%d unexpected error %v", i, result.Err)
+		}
+	}
+}
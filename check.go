@@ -0,0 +1,163 @@
+// Copyright © 2023 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package accelhint
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/exp/slices"
+)
+
+type DiagnosticKind uint8
+
+const (
+	DuplicateAccelerator DiagnosticKind = iota
+	NonAlphabetAccelerator
+	DanglingMarker
+	WhitespaceMarker
+	MissingAccelerator
+	UnescapedMarker
+)
+
+func (kind DiagnosticKind) String() string {
+	switch kind {
+	case DuplicateAccelerator:
+		return "duplicate accelerator"
+	case NonAlphabetAccelerator:
+		return "accelerator not in alphabet"
+	case DanglingMarker:
+		return "dangling marker"
+	case WhitespaceMarker:
+		return "marker before whitespace"
+	case MissingAccelerator:
+		return "missing accelerator"
+	case UnescapedMarker:
+		return "unescaped marker"
+	}
+	return fmt.Sprintf("DiagnosticKind(%d)", kind)
+}
+
+// Diagnostic is a problem found by Check. Row is the item's index and
+// Column is the (rune) index in the item of the offending marker, or for
+// MissingAccelerator, of the first character that could have been used.
+// Char is the accelerator (or candidate) character if there is one, and
+// OtherRow is the row that first used the accelerator for
+// DuplicateAccelerator (otherwise -1).
+type Diagnostic struct {
+	Kind     DiagnosticKind
+	Row      int
+	Column   int
+	Char     rune
+	OtherRow int
+}
+
+func (diagnostic Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s", diagnostic.Row, diagnostic.Column,
+		diagnostic.Message())
+}
+
+// Returns the diagnostic's description without its position.
+func (diagnostic Diagnostic) Message() string {
+	switch diagnostic.Kind {
+	case DuplicateAccelerator:
+		return fmt.Sprintf("%s %q (also in row %d)", diagnostic.Kind,
+			diagnostic.Char, diagnostic.OtherRow)
+	case NonAlphabetAccelerator, MissingAccelerator:
+		return fmt.Sprintf("%s %q", diagnostic.Kind, diagnostic.Char)
+	}
+	return diagnostic.Kind.String()
+}
+
+// Returns diagnostics for the problems in items which are already hinted
+// using the given marker, without modifying them. Only characters in the
+// Alphabet are valid accelerators.
+// See also CheckX and Options.Check.
+func Check(items []string, marker byte) []Diagnostic {
+	return CheckX(items, marker, Alphabet)
+}
+
+// Returns diagnostics for the problems in items which are already hinted
+// using the given marker, without modifying them. Only characters in the
+// given alphabet (of unique uppercase characters) are valid accelerators.
+// Any text from a ShortcutSeparator onwards is ignored (as Hinted does). An
+// item with no accelerator is only reported if it has a character in the
+// alphabet that isn't used by any other item, and isn't a separator.
+// See also Check and Options.Check.
+func CheckX(items []string, marker byte, alphabet string) []Diagnostic {
+	options := NewOptions()
+	options.Marker = marker
+	options.Alphabet = alphabet
+	return options.Check(items)
+}
+
+// Returns diagnostics for the problems in items which are already hinted
+// using the Options' Marker, without modifying them, as CheckX does, but
+// using the Options' ShortcutSeparator, Skip, and CaseSensitive settings.
+// See also Check and CheckX.
+func (options *Options) Check(items []string) []Diagnostic {
+	marker, alphabet := options.Marker, options.Alphabet
+	var diagnostics []Diagnostic
+	seen := make(map[rune]int) // key=char value=row in items
+	var unaccelerated []int
+	for row, item := range items {
+		chars := []rune(withoutShortcut(item, options.ShortcutSeparator))
+		accelerated := false
+		for column := 0; column < len(chars); column++ {
+			if chars[column] != rune(marker) {
+				continue
+			}
+			if column+1 < len(chars) && chars[column+1] == rune(marker) {
+				column++ // skip literal marker
+				continue
+			}
+			diagnostic := Diagnostic{Row: row, Column: column, OtherRow: -1}
+			c := markedChar(string(chars[column+1:]))
+			if c == 0 {
+				diagnostic.Kind = DanglingMarker
+			} else if accelerated {
+				diagnostic.Kind = UnescapedMarker
+			} else {
+				accelerated = true
+				diagnostic.Char = c
+				u := options.upper(c)
+				if unicode.IsSpace(c) {
+					diagnostic.Kind = WhitespaceMarker
+				} else if !strings.ContainsRune(alphabet, u) {
+					diagnostic.Kind = NonAlphabetAccelerator
+				} else if firstRow, found := seen[u]; found {
+					diagnostic.Kind = DuplicateAccelerator
+					diagnostic.OtherRow = firstRow
+				} else {
+					seen[u] = row
+					continue // valid accelerator
+				}
+			}
+			diagnostics = append(diagnostics, diagnostic)
+		}
+		if !accelerated && (options.Skip == nil || !options.Skip(row, item)) {
+			unaccelerated = append(unaccelerated, row)
+		}
+	}
+	for _, row := range unaccelerated {
+		m := string(marker)
+		chars := []rune(strings.ReplaceAll(withoutShortcut(items[row],
+			options.ShortcutSeparator), m+m, placeholder))
+		for column, c := range chars {
+			u := options.upper(c)
+			if _, found := seen[u]; !found &&
+				strings.ContainsRune(alphabet, u) {
+				diagnostics = append(diagnostics, Diagnostic{
+					Kind: MissingAccelerator, Row: row, Column: column,
+					Char: c, OtherRow: -1})
+				break
+			}
+		}
+	}
+	slices.SortStableFunc(diagnostics, func(a, b Diagnostic) bool {
+		return a.Row < b.Row || (a.Row == b.Row && a.Column < b.Column)
+	})
+	return diagnostics
+}
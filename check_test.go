@@ -0,0 +1,92 @@
+package accelhint
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestCheck1(t *testing.T) {
+	hinted, _, err := Hinted([]string{"Undo", "Redo", "Cu&t",
+		"Find && Replace"})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if diagnostics := Check(hinted, '&'); len(diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diagnostics)
+	}
+}
+
+func TestCheck2(t *testing.T) {
+	items := []string{
+		"&Copy",
+		"&Cut",        // duplicate
+		"Save & Exit", // whitespace
+		"Help&",       // dangling
+		"&Open &Recent",
+		"&(Optional)",
+		"Paste",
+		"Close && Quit",
+	}
+	expected := []string{
+		"1:0: duplicate accelerator 'C' (also in row 0)",
+		"2:5: marker before whitespace",
+		"3:0: missing accelerator 'H'",
+		"3:4: dangling marker",
+		"4:6: unescaped marker",
+		"5:0: accelerator not in alphabet '('",
+		"6:0: missing accelerator 'P'",
+		"7:1: missing accelerator 'l'",
+	}
+	diagnostics := Check(items, '&')
+	messages := make([]string, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		messages = append(messages, diagnostic.String())
+	}
+	if !slices.Equal(messages, expected) {
+		t.Errorf("expected\n%q\ngot\n%q", expected, messages)
+	}
+}
+
+func TestCheckShortcuts(t *testing.T) {
+	items := []string{"&Ab", "&Ba", "Ab\tCtrl+X", "&Find\tCtrl+&F", "-"}
+	expected := []string{} // shortcut text can't be used so isn't checked
+	diagnostics := Check(items, '&')
+	messages := make([]string, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		messages = append(messages, diagnostic.String())
+	}
+	if !slices.Equal(messages, expected) {
+		t.Errorf("expected\n%q\ngot\n%q", expected, messages)
+	}
+	options := NewOptions()
+	options.ShortcutSeparator = ""
+	options.Skip = nil
+	expected = []string{"2:3: missing accelerator 'C'",
+		"3:11: unescaped marker"}
+	diagnostics = options.Check(items)
+	messages = messages[:0]
+	for _, diagnostic := range diagnostics {
+		messages = append(messages, diagnostic.String())
+	}
+	if !slices.Equal(messages, expected) {
+		t.Errorf("expected\n%q\ngot\n%q", expected, messages)
+	}
+}
+
+func TestCheckSkip(t *testing.T) {
+	items := []string{"&Open", "── Recent ──", "Close"}
+	options := NewOptions()
+	options.Skip = func(row int, item string) bool {
+		return row == 1 || SkipSeparators(row, item)
+	}
+	expected := []string{"2:0: missing accelerator 'C'"} // not the heading
+	diagnostics := options.Check(items)
+	messages := make([]string, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		messages = append(messages, diagnostic.String())
+	}
+	if !slices.Equal(messages, expected) {
+		t.Errorf("expected\n%q\ngot\n%q", expected, messages)
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright © 2023 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+// Command accelhint sets or checks keyboard accelerators in files of
+// labels (one per line) or JSON/YAML menu documents.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark-summerfield/accelhint"
+)
+
+const usage = `usage: accelhint <command> [options] [file ...]
+
+commands:
+  hint    write the labels (one per line) with accelerators set
+  check   report accelerator problems in the labels (one per line)
+  explain explain the accelerator chosen for each label (one per line)
+  menu    write the JSON or YAML menu document with accelerators set
+
+The hint, check, and explain commands read labels from the given files (or from
+stdin); the menu command reads a file ending .json, .yaml, or .yml.
+Use "accelhint <command> -h" for the command's options.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch command, args := os.Args[1], os.Args[2:]; command {
+	case "hint":
+		err = hint(args)
+	case "check":
+		var ok bool
+		if ok, err = check(args); err == nil && !ok {
+			os.Exit(1)
+		}
+	case "explain":
+		err = explain(args)
+	case "menu":
+		err = menu(args)
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n%s", command, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+type config struct {
+	flags    *flag.FlagSet
+	marker   string
+	alphabet string
+	words    string
+	assigner string
+}
+
+var wordStarts = map[string]accelhint.WordStartsFunc{
+	"space": accelhint.SpaceWordStarts,
+	"punct": accelhint.PunctuationWordStarts,
+	"camel": accelhint.CamelCaseWordStarts,
+	"uax29": accelhint.UAX29WordStarts,
+}
+
+var assigners = map[string]accelhint.Assigner{
+	"hungarian": accelhint.Hungarian,
+	"jv":        accelhint.JonkerVolgenant,
+	"greedy":    accelhint.Greedy,
+}
+
+func newConfig(name string) *config {
+	config := &config{flags: flag.NewFlagSet(name, flag.ExitOnError)}
+	config.flags.StringVar(&config.marker, "marker",
+		string(accelhint.Marker), "the accelerator marker (ASCII)")
+	config.flags.StringVar(&config.alphabet, "alphabet", accelhint.Alphabet,
+		"the candidate accelerator characters (uppercase)")
+	config.flags.StringVar(&config.words, "words", "space",
+		"what separates words: space, punct, camel, or uax29")
+	config.flags.StringVar(&config.assigner, "assigner", "hungarian",
+		"how to assign accelerators: hungarian, jv, or greedy")
+	return config
+}
+
+func (config *config) parse(args []string) (*accelhint.Options, error) {
+	_ = config.flags.Parse(args) // exits on error
+	if len(config.marker) != 1 {
+		return nil, fmt.Errorf("invalid marker %q", config.marker)
+	}
+	options := accelhint.NewOptions()
+	options.Marker = config.marker[0]
+	options.Alphabet = config.alphabet
+	var found bool
+	if options.WordStarts, found = wordStarts[config.words]; !found {
+		return nil, fmt.Errorf("invalid words %q", config.words)
+	}
+	if options.Assigner, found = assigners[config.assigner]; !found {
+		return nil, fmt.Errorf("invalid assigner %q", config.assigner)
+	}
+	return options, nil
+}
+
+var styles = map[string]func(accelhint.Segments) string{
+	"brackets":  accelhint.Segments.Bracketed,
+	"underline": accelhint.Segments.Underlined,
+	"bold":      accelhint.Segments.Bold,
+}
+
+func hint(args []string) error {
+	config := newConfig("hint")
+	var style string
+	config.flags.StringVar(&style, "style", "marker",
+		"how to show accelerators: marker, brackets, underline, or bold")
+	options, err := config.parse(args)
+	if err != nil {
+		return err
+	}
+	render, found := styles[style]
+	if !found && style != "marker" {
+		return fmt.Errorf("invalid style %q", style)
+	}
+	return forEachFile(config.flags.Args(), func(name string,
+		lines []string) error {
+		hinted, _, err := options.Hinted(lines)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if render != nil {
+			for i, segments := range accelhint.SplitX(hinted,
+				options.Marker) {
+				hinted[i] = render(segments)
+			}
+		}
+		for _, line := range hinted {
+			fmt.Println(line)
+		}
+		return nil
+	})
+}
+
+func check(args []string) (bool, error) {
+	config := newConfig("check")
+	options, err := config.parse(args)
+	if err != nil {
+		return false, err
+	}
+	ok := true
+	err = forEachFile(config.flags.Args(), func(name string,
+		lines []string) error {
+		for _, diagnostic := range options.Check(lines) {
+			ok = false
+			message := diagnostic.Message()
+			if diagnostic.Kind == accelhint.DuplicateAccelerator {
+				message = fmt.Sprintf("%s %q (also on line %d)",
+					diagnostic.Kind, diagnostic.Char, diagnostic.OtherRow+1)
+			}
+			fmt.Printf("%s:%d:%d: %s\n", name, diagnostic.Row+1,
+				diagnostic.Column+1, message)
+		}
+		return nil
+	})
+	return ok, err
+}
+
+func explain(args []string) error {
+	config := newConfig("explain")
+	options, err := config.parse(args)
+	if err != nil {
+		return err
+	}
+	return forEachFile(config.flags.Args(), func(name string,
+		lines []string) error {
+		explanations, err := options.Explain(lines)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		for _, explanation := range explanations {
+			fmt.Println(explanation)
+		}
+		return nil
+	})
+}
+
+func menu(args []string) error {
+	flags := flag.NewFlagSet("menu", flag.ExitOnError)
+	_ = flags.Parse(args) // exits on error
+	if flags.NArg() != 1 {
+		return fmt.Errorf("menu: expected one .json, .yaml, or .yml file")
+	}
+	name := flags.Arg(0)
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var menu *accelhint.Menu
+	yaml := isYAML(name)
+	if yaml {
+		menu, err = accelhint.ReadMenuYAML(file)
+	} else {
+		menu, err = accelhint.ReadMenuJSON(file)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if _, err = menu.Hint(); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if yaml {
+		return menu.WriteYAML(os.Stdout)
+	}
+	return menu.WriteJSON(os.Stdout)
+}
+
+// Calls action with the lines of each named file, or of stdin if there are
+// no names.
+func forEachFile(names []string, action func(string, []string) error) error {
+	if len(names) == 0 {
+		lines, err := readLines(os.Stdin)
+		if err != nil {
+			return err
+		}
+		return action("-", lines)
+	}
+	for _, name := range names {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		lines, err := readLines(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err = action(name, lines); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readLines(reader io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+func isYAML(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yaml" || ext == ".yml"
+}
@@ -0,0 +1,141 @@
+// Copyright © 2023 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package accelhint
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Candidate is an accelerator character that was considered for an item
+// with the item's claim on it (higher is better) as used for the
+// assignment: 99 for a preset, 4 for the first char, 2 for a word start,
+// and 1 anywhere else (as adjusted by the Preferred and Importance
+// Options), less a fraction of at most 1/1024 that prefers earlier chars
+// and later items.
+type Candidate struct {
+	Char  rune
+	Claim float64
+}
+
+// Explanation describes how an item's accelerator was chosen. Accelerator
+// is rune(0) if the item has none. Candidates are best first (and are
+// empty if the item wasn't considered, e.g., because there were more items
+// than characters in the alphabet). If the best candidate isn't the
+// accelerator, TakenBy is the row of the item that took it (or -1 if none
+// did, e.g., because the item had a preset).
+type Explanation struct {
+	Item        string
+	Hinted      string
+	Accelerator rune
+	Preset      bool
+	Candidates  []Candidate
+	TakenBy     int
+}
+
+func (explanation Explanation) String() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "%q → %q: ", explanation.Item, explanation.Hinted)
+	switch {
+	case explanation.Preset:
+		fmt.Fprintf(&out, "preset %q", explanation.Accelerator)
+	case explanation.Accelerator == 0:
+		out.WriteString("no accelerator")
+	default:
+		fmt.Fprintf(&out, "chose %q", explanation.Accelerator)
+	}
+	if explanation.TakenBy > -1 {
+		fmt.Fprintf(&out, "; %q taken by row %d",
+			explanation.Candidates[0].Char, explanation.TakenBy)
+	}
+	if len(explanation.Candidates) == 0 {
+		out.WriteString("; not considered")
+	} else {
+		out.WriteString("; claims:")
+		for _, candidate := range explanation.Candidates {
+			fmt.Fprintf(&out, " %c=%.4f", candidate.Char, candidate.Claim)
+		}
+	}
+	return out.String()
+}
+
+// Returns an explanation of the accelerator chosen for each item by
+// Hinted.
+// See also ExplainX.
+func Explain(items []string) ([]Explanation, error) {
+	return ExplainX(items, Marker, Alphabet)
+}
+
+// Returns an explanation of the accelerator chosen for each item by
+// HintedX with the same arguments.
+// See also Explain.
+func ExplainX(items []string, marker byte, alphabet string) ([]Explanation,
+	error) {
+	options := NewOptions()
+	options.Marker = marker
+	options.Alphabet = alphabet
+	return options.Explain(items)
+}
+
+// Returns an explanation of the accelerator chosen for each item by the
+// Options' Hinted method.
+// See also Explain and ExplainX.
+func (options *Options) Explain(items []string) ([]Explanation, error) {
+	alphabet := []rune(options.Alphabet)
+	assignment, err := assign(items, options, alphabet)
+	if err != nil {
+		return nil, err
+	}
+	hinted, _, err := applyIndexes(items, options, alphabet, assignment)
+	if err != nil {
+		return nil, err
+	}
+	accels := AcceleratorsX(hinted, options.Marker)
+	rows := make(map[rune]int, len(accels)) // key=char value=row in items
+	for row, accel := range accels {
+		if accel != 0 {
+			rows[options.upper(accel)] = row
+		}
+	}
+	explanations := make([]Explanation, 0, len(items))
+	for row, item := range items {
+		explanation := Explanation{Item: item, Hinted: hinted[row],
+			Accelerator: accels[row], TakenBy: -1}
+		explanation.Preset = explanation.Accelerator != 0 &&
+			AcceleratorsX([]string{item}, options.Marker)[0] != 0
+		if r := slices.Index(assignment.rows, row); r > -1 {
+			explanation.Candidates = assignment.candidatesFor(r, alphabet)
+		}
+		if len(explanation.Candidates) > 0 {
+			best := explanation.Candidates[0].Char
+			if best != options.upper(explanation.Accelerator) {
+				if takenBy, found := rows[best]; found {
+					explanation.TakenBy = takenBy
+				}
+			}
+		}
+		explanations = append(explanations, explanation)
+	}
+	return explanations, nil
+}
+
+// Returns the candidates for the given row of the assignment with their
+// claims in units, best first.
+func (assignment *assignment) candidatesFor(row int,
+	alphabet []rune) []Candidate {
+	var candidates []Candidate
+	for column, weight := range assignment.weights[row] {
+		if weight < maxWeight {
+			candidates = append(candidates, Candidate{
+				Char:  alphabet[assignment.keys[column]],
+				Claim: float64(maxWeight-weight) / weightUnit})
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b Candidate) bool {
+		return a.Claim > b.Claim
+	})
+	return candidates
+}
@@ -0,0 +1,70 @@
+package accelhint
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExplain1(t *testing.T) {
+	items := []string{"Undo", "Cu&t", "Find", "Find Again", "Redo",
+		"Find && Replace"}
+	explanations, err := Explain(items)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []struct {
+		hinted  string
+		accel   rune
+		preset  bool
+		best    rune
+		takenBy int
+	}{
+		{"&Undo", 'U', false, 'U', -1},
+		{"Cu&t", 't', true, 'T', -1},
+		{"&Find", 'F', false, 'F', -1},
+		{"Find &Again", 'A', false, 'F', 2},
+		{"&Redo", 'R', false, 'R', -1},
+		{"F&ind && Replace", 'i', false, 'F', 2},
+	}
+	for i, explanation := range explanations {
+		want := expected[i]
+		if explanation.Hinted != want.hinted ||
+			explanation.Accelerator != want.accel ||
+			explanation.Preset != want.preset ||
+			explanation.Candidates[0].Char != want.best ||
+			explanation.TakenBy != want.takenBy {
+			t.Errorf("#%d: expected %v, got %s", i, want, explanation)
+		}
+	}
+	last := explanations[len(explanations)-1]
+	if len(last.Candidates) != 10 {
+		t.Errorf("expected 10 candidates, got %s", last)
+	}
+	for i := 1; i < len(last.Candidates); i++ {
+		if last.Candidates[i-1].Claim < last.Candidates[i].Claim {
+			t.Errorf("expected candidates in claim order, got %s", last)
+		}
+	}
+	for i, expected := range []float64{4, 2, 1} { // F, R, I
+		if claim := last.Candidates[i].Claim; math.Round(claim) != expected ||
+			claim > expected || claim < expected-1.0/claimUnit {
+			t.Errorf("#%d: expected a claim of about %v, got %s", i,
+				expected, last)
+		}
+	}
+}
+
+func TestExplain2(t *testing.T) {
+	explanations, err := ExplainX([]string{"ab", "ba", "ab"}, '&', "AB")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(explanations[2].Candidates) != 0 ||
+		explanations[2].Accelerator != 0 {
+		t.Errorf("expected unconsidered item, got %s", explanations[2])
+	}
+	expected := `"ab" → "ab": no accelerator; not considered`
+	if s := explanations[2].String(); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+}
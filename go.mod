@@ -4,5 +4,8 @@ go 1.19
 
 require (
 	github.com/charles-haynes/munkres v0.0.0-20191008174651-55d467190535
+	github.com/rivo/uniseg v0.4.7
 	golang.org/x/exp v0.0.0-20230108222341-4b8118a2686a
+	golang.org/x/text v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,176 @@
+// Copyright © 2023 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package accelhint
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/slices"
+)
+
+// Hinter hints items using the given Options, reusing its cost matrices and
+// other buffers from one call to the next, so it is best for menus that are
+// hinted over and over (e.g., context menus that are rebuilt every time
+// they pop up). With the JonkerVolgenant Assigner (or one from
+// NewJonkerVolgenant), a Hinter solves using pooled buffers of its own too,
+// so each call only allocates the returned slice and the hinted items; the
+// default Hungarian Assigner allocates its own working storage every call.
+// A Hinter is safe for concurrent use, providing its Options aren't changed
+// while it is in use.
+//
+// A Hinter made by NewCachedHinter also memoises its results keyed by the
+// items and the Options, keeping those most recently used. Results are only
+// cached if the Options' function-valued fields (e.g., WordStarts) and
+// Assigner are nil or the package's own, since others (e.g., closures) may
+// have state that can't be compared.
+type Hinter struct {
+	Options *Options
+	buffers sync.Pool // of *hinterBuffers
+	mutex   sync.Mutex
+	size    int                      // maximum number of cached results
+	recent  *list.List               // of *hinterResult, most recent first
+	results map[string]*list.Element // key=items & options value=result
+}
+
+type hinterBuffers struct {
+	text       string
+	alphabet   []rune
+	assignment assignment
+}
+
+type hinterResult struct {
+	key    string
+	hinted []string
+	count  int
+	err    error
+}
+
+// Returns a Hinter that uses the given Options (or NewOptions() if nil).
+// The Options may be changed between calls to Hinted.
+// See also NewCachedHinter.
+func NewHinter(options *Options) *Hinter {
+	return NewCachedHinter(options, 0)
+}
+
+// Returns a Hinter that uses the given Options (or NewOptions() if nil) and
+// which memoises the results of up to size calls to Hinted (or none if size
+// is 0), discarding the least recently used first.
+// See also NewHinter.
+func NewCachedHinter(options *Options, size int) *Hinter {
+	if options == nil {
+		options = NewOptions()
+	}
+	hinter := &Hinter{Options: options, size: size}
+	if size > 0 {
+		hinter.recent = list.New()
+		hinter.results = make(map[string]*list.Element, size)
+	}
+	return hinter
+}
+
+// Returns items with markers to indicate accelerators, and the number
+// accelerated, exactly as the Options' Hinted method does. The returned
+// slice belongs to the caller even if the result was cached.
+// See also Hinted and HintedX.
+func (hinter *Hinter) Hinted(items []string) ([]string, int, error) {
+	return hinter.hintedContext(context.Background(), items)
+}
+
+// Returns items hinted as for Hinted, or the context's error if it is done
+// before they are solved.
+func (hinter *Hinter) hintedContext(ctx context.Context, items []string) (
+	[]string, int, error) {
+	if hinter.size == 0 {
+		return hinter.hinted(ctx, items)
+	}
+	key, ok := hinter.key(items)
+	if !ok {
+		return hinter.hinted(ctx, items)
+	}
+	hinter.mutex.Lock()
+	if element, found := hinter.results[key]; found {
+		hinter.recent.MoveToFront(element)
+		result := element.Value.(*hinterResult)
+		hinter.mutex.Unlock()
+		return slices.Clone(result.hinted), result.count, result.err
+	}
+	hinter.mutex.Unlock()
+	hinted, count, err := hinter.hinted(ctx, items)
+	if err != nil && err == ctx.Err() {
+		return nil, 0, err // cancelled so there's no result to cache
+	}
+	hinter.mutex.Lock()
+	defer hinter.mutex.Unlock()
+	if _, found := hinter.results[key]; !found { // another may have added it
+		hinter.results[key] = hinter.recent.PushFront(&hinterResult{key: key,
+			hinted: slices.Clone(hinted), count: count, err: err})
+		if hinter.recent.Len() > hinter.size {
+			oldest := hinter.recent.Remove(hinter.recent.Back())
+			delete(hinter.results, oldest.(*hinterResult).key)
+		}
+	}
+	return hinted, count, err
+}
+
+// Returns the number of results that are cached.
+func (hinter *Hinter) Len() int {
+	if hinter.size == 0 {
+		return 0
+	}
+	hinter.mutex.Lock()
+	defer hinter.mutex.Unlock()
+	return hinter.recent.Len()
+}
+
+// Discards all the cached results.
+func (hinter *Hinter) Reset() {
+	if hinter.size == 0 {
+		return
+	}
+	hinter.mutex.Lock()
+	defer hinter.mutex.Unlock()
+	hinter.recent.Init()
+	hinter.results = make(map[string]*list.Element, hinter.size)
+}
+
+func (hinter *Hinter) hinted(ctx context.Context, items []string) (
+	[]string, int, error) {
+	buffers, ok := hinter.buffers.Get().(*hinterBuffers)
+	if !ok {
+		buffers = &hinterBuffers{}
+	}
+	defer hinter.buffers.Put(buffers)
+	if buffers.alphabet == nil || buffers.text != hinter.Options.Alphabet {
+		buffers.text = hinter.Options.Alphabet
+		buffers.alphabet = []rune(buffers.text)
+	}
+	err := buffers.assignment.assign(ctx, items, hinter.Options,
+		buffers.alphabet)
+	if err != nil {
+		return nil, 0, err
+	}
+	return applyIndexes(items, hinter.Options, buffers.alphabet,
+		&buffers.assignment)
+}
+
+// Returns the cache key for the items with the Hinter's Options and true,
+// or "" and false if the results mustn't be cached.
+func (hinter *Hinter) key(items []string) (string, bool) {
+	fingerprint, ok := hinter.Options.fingerprint()
+	if !ok {
+		return "", false
+	}
+	var key strings.Builder
+	key.WriteString(fingerprint)
+	for _, item := range items {
+		key.WriteString(strconv.Itoa(len(item)))
+		key.WriteByte(':')
+		key.WriteString(item)
+	}
+	return key.String(), true
+}
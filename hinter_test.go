@@ -0,0 +1,261 @@
+package accelhint
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+var (
+	contextMenu = []string{"Cu&t", "Copy", "Paste", "Delete", "-",
+		"Select All"}
+	fileMenu = []string{"New", "New Window", "Open...", "Open Recent", "-",
+		"Save\tCtrl+S", "Save As...\tCtrl+Shift+S", "Save All", "Revert",
+		"-", "Print...", "Print Preview", "Page Setup...", "-", "Close",
+		"Close All", "Exit"}
+	editMenu = []string{"Undo\tCtrl+Z", "Redo\tCtrl+Y", "-", "Cut\tCtrl+X",
+		"Copy\tCtrl+C", "Paste\tCtrl+V", "Paste Special...", "Delete",
+		"-", "Find...\tCtrl+F", "Find Next\tF3", "Find Previous",
+		"Replace...\tCtrl+H", "Go To Line...", "-", "Select All",
+		"Select Word", "Select Line", "-", "Preferences"}
+)
+
+// Returns a menu of n items many of which have words in common.
+func bigMenu(n int) []string {
+	words := []string{"Open", "Recent", "Project", "Window", "Layout",
+		"Toggle", "Panel", "Split", "Editor", "Terminal"}
+	items := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		items = append(items, fmt.Sprintf("%s %s %d", words[i%len(words)],
+			words[(i/len(words)+i)%len(words)], i))
+	}
+	return items
+}
+
+func TestHinter(t *testing.T) {
+	hinter := NewHinter(nil)
+	menus := [][]string{contextMenu, fileMenu, editMenu, bigMenu(40),
+		contextMenu, {"One"}, {}, fileMenu, bigMenu(8)}
+	for i, menu := range menus {
+		expected, expectedCount, err := Hinted(menu)
+		if err != nil {
+			t.Errorf("#%d unexpected error: %s", i, err)
+		}
+		hinted, count, err := hinter.Hinted(menu)
+		if err != nil {
+			t.Errorf("#%d unexpected error: %s", i, err)
+		}
+		if count != expectedCount || !slices.Equal(hinted, expected) {
+			t.Errorf("#%d expected %q (%d), got %q (%d)", i, expected,
+				expectedCount, hinted, count)
+		}
+	}
+	hinter.Options.Alphabet = "ABC"
+	hinted, count, err := hinter.Hinted([]string{"Cat", "Bat", "Axe"})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected := []string{"&Cat", "&Bat", "&Axe"}
+	if count != 3 || !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q (%d)", expected, hinted, count)
+	}
+	if _, _, err = hinter.Hinted([]string{"&Cat", "&Cow"}); err == nil {
+		t.Error("expected duplicate accelerator error")
+	}
+	hinted, _, err = hinter.Hinted([]string{"Cow", "Bow"})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected = []string{"&Cow", "&Bow"}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+}
+
+func TestHinterCache(t *testing.T) {
+	hinter := NewCachedHinter(nil, 2)
+	expected, _, _ := Hinted(fileMenu)
+	for i := 0; i < 2; i++ {
+		hinted, _, err := hinter.Hinted(fileMenu)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if !slices.Equal(hinted, expected) {
+			t.Errorf("#%d expected %q, got %q", i, expected, hinted)
+		}
+		hinted[0] = "changed" // mustn't change the cached result
+	}
+	if hinter.Len() != 1 {
+		t.Errorf("expected 1 cached, got %d", hinter.Len())
+	}
+	if _, _, err := hinter.Hinted([]string{"&Cat", "&Cow"}); err == nil {
+		t.Error("expected duplicate accelerator error")
+	}
+	if _, _, err := hinter.Hinted([]string{"&Cat", "&Cow"}); err == nil {
+		t.Error("expected cached duplicate accelerator error")
+	}
+	hinter.Hinted(editMenu) // evicts fileMenu
+	if hinter.Len() != 2 {
+		t.Errorf("expected 2 cached, got %d", hinter.Len())
+	}
+	hinter.Options.Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	expected, _, _ = HintedX(editMenu, Marker, hinter.Options.Alphabet)
+	hinted, _, _ := hinter.Hinted(editMenu)
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	hinter.Options.WordStarts = CamelCaseWordStarts
+	items := []string{"getValue", "setValue", "gv"}
+	expected, _, _ = hinter.Options.Hinted(items)
+	hinted, _, _ = hinter.Hinted(items)
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	hinter.Reset()
+	if hinter.Len() != 0 {
+		t.Errorf("expected 0 cached, got %d", hinter.Len())
+	}
+	items = []string{"Open", "Save", "Quit"}
+	for _, skipped := range []int{0, 1, 2} { // closures aren't cached
+		hinter.Options.Skip = func(row int, _ string) bool {
+			return row == skipped
+		}
+		expected, _, _ = hinter.Options.Hinted(items)
+		hinted, _, _ = hinter.Hinted(items)
+		if !slices.Equal(hinted, expected) {
+			t.Errorf("expected %q, got %q", expected, hinted)
+		}
+		if hinter.Len() != 0 {
+			t.Errorf("expected 0 cached, got %d", hinter.Len())
+		}
+	}
+}
+
+func TestHinterConcurrent(t *testing.T) {
+	menus := [][]string{contextMenu, fileMenu, editMenu, bigMenu(40)}
+	expected := make([][]string, 0, len(menus))
+	for _, menu := range menus {
+		hinted, _, _ := Hinted(menu)
+		expected = append(expected, hinted)
+	}
+	for _, hinter := range []*Hinter{NewHinter(nil),
+		NewCachedHinter(nil, 2)} {
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					j := (g + i) % len(menus)
+					hinted, _, err := hinter.Hinted(menus[j])
+					if err != nil {
+						t.Errorf("unexpected error: %s", err)
+					}
+					if !slices.Equal(hinted, expected[j]) {
+						t.Errorf("expected %q, got %q", expected[j], hinted)
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+	}
+}
+
+func benchmarkMenus() map[string][]string {
+	return map[string][]string{"context": contextMenu, "file": fileMenu,
+		"edit": editMenu, "big": bigMenu(60)}
+}
+
+func BenchmarkHinted(b *testing.B) {
+	for _, name := range []string{"context", "file", "edit", "big"} {
+		menu := benchmarkMenus()[name]
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := Hinted(menu); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkHinter(b *testing.B) {
+	for _, name := range []string{"context", "file", "edit", "big"} {
+		menu := benchmarkMenus()[name]
+		b.Run(name, func(b *testing.B) {
+			hinter := NewHinter(nil)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := hinter.Hinted(menu); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCachedHinter(b *testing.B) {
+	for _, name := range []string{"context", "file", "edit", "big"} {
+		menu := benchmarkMenus()[name]
+		b.Run(name, func(b *testing.B) {
+			hinter := NewCachedHinter(nil, 10)
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, _, err := hinter.Hinted(menu); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkAssigners(b *testing.B) {
+	menu := bigMenu(60)
+	for _, name := range []string{"hungarian", "jv", "jv-reused",
+		"greedy"} {
+		assigner := map[string]Assigner{"hungarian": Hungarian,
+			"jv": JonkerVolgenant, "jv-reused": NewJonkerVolgenant(),
+			"greedy": Greedy}[name]
+		b.Run(name, func(b *testing.B) {
+			options := NewOptions()
+			options.Assigner = assigner
+			hinter := NewHinter(options)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := hinter.Hinted(menu); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+var raceEnabled bool // see race_test.go
+
+func TestHinterAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the Hinter's buffers aren't reliably reused under -race")
+	}
+	options := NewOptions()
+	options.Assigner = JonkerVolgenant
+	hinter := NewHinter(options)
+	for _, menu := range [][]string{contextMenu, fileMenu, editMenu,
+		bigMenu(60)} {
+		_, count, err := hinter.Hinted(menu)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		allocs := testing.AllocsPerRun(100, func() {
+			_, _, _ = hinter.Hinted(menu)
+		})
+		// Only the returned slice and the accelerated items
+		if limit := float64(count + 1); allocs > limit {
+			t.Errorf("expected at most %.0f allocs, got %.1f", limit, allocs)
+		}
+	}
+}
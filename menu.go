@@ -0,0 +1,334 @@
+// Copyright © 2023 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package accelhint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// Menu is a menu (or dialog) document. Its Items form the top-level scope
+// and each item's Items form a nested scope: accelerators are unique
+// within a scope. Marker and Alphabet are optional and default to Marker
+// and Alphabet. Reserved holds keys that must not be used in the top-level
+// scope. Fields that Menu doesn't know about are kept in Extra and written
+// back unchanged.
+type Menu struct {
+	Marker   string         `yaml:"marker,omitempty"`
+	Alphabet string         `yaml:"alphabet,omitempty"`
+	Reserved string         `yaml:"reserved,omitempty"`
+	Items    []*MenuItem    `yaml:"items,omitempty"`
+	Extra    map[string]any `yaml:",inline"`
+}
+
+// MenuItem is an item in a Menu. Preset is an optional accelerator which
+// must be used for the Label (the same as writing the Label with a marker).
+// If Skip is true (or the Label is a separator such as "-") the Label
+// never gets an accelerator. Reserved holds keys that must not be used in
+// the item's Items. Fields that MenuItem doesn't know about are kept in
+// Extra and written back unchanged.
+type MenuItem struct {
+	ID       string         `yaml:"id,omitempty"`
+	Label    string         `yaml:"label"`
+	Preset   string         `yaml:"preset,omitempty"`
+	Skip     bool           `yaml:"skip,omitempty"`
+	Reserved string         `yaml:"reserved,omitempty"`
+	Items    []*MenuItem    `yaml:"items,omitempty"`
+	Extra    map[string]any `yaml:",inline"`
+}
+
+// Returns the Menu read from JSON.
+// See also ReadMenuYAML.
+func ReadMenuJSON(reader io.Reader) (*Menu, error) {
+	menu := &Menu{}
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	if err := decoder.Decode(menu); err != nil {
+		return nil, err
+	}
+	return menu, nil
+}
+
+// Returns the Menu read from YAML.
+// See also ReadMenuJSON.
+func ReadMenuYAML(reader io.Reader) (*Menu, error) {
+	menu := &Menu{}
+	if err := yaml.NewDecoder(reader).Decode(menu); err != nil {
+		return nil, err
+	}
+	return menu, nil
+}
+
+// Writes the Menu as indented JSON.
+// See also WriteYAML.
+func (menu *Menu) WriteJSON(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(menu)
+}
+
+// Writes the Menu as YAML.
+// See also WriteJSON.
+func (menu *Menu) WriteYAML(writer io.Writer) error {
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(menu); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// Sets accelerators for the items in every scope of the Menu, and returns
+// how many items were accelerated in total. Labels that already have an
+// accelerator (or a Preset) keep it.
+func (menu *Menu) Hint() (int, error) {
+	marker := byte(Marker)
+	if menu.Marker != "" {
+		if len(menu.Marker) != 1 {
+			return 0, fmt.Errorf("invalid marker %q", menu.Marker)
+		}
+		marker = menu.Marker[0]
+	}
+	alphabet := Alphabet
+	if menu.Alphabet != "" {
+		alphabet = menu.Alphabet
+	}
+	return hintMenuItems(menu.Items, "", marker, alphabet, menu.Reserved)
+}
+
+func hintMenuItems(items []*MenuItem, path string, marker byte,
+	alphabet, reserved string) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+	scopeAlphabet := withoutReserved(alphabet, reserved)
+	labels := make([]string, 0, len(items))
+	for _, item := range items {
+		label, err := presetLabel(item, marker, scopeAlphabet)
+		if err != nil {
+			return 0, fmt.Errorf("menu %s: %w", itemPath(path, item), err)
+		}
+		labels = append(labels, label)
+	}
+	options := NewOptions()
+	options.Marker = marker
+	options.Alphabet = scopeAlphabet
+	options.Skip = func(row int, label string) bool {
+		return items[row].Skip || SkipSeparators(row, label)
+	}
+	labels, total, err := options.Hinted(labels)
+	if err != nil {
+		return 0, fmt.Errorf("menu %s: %w", scopePath(path), err)
+	}
+	for i, item := range items {
+		item.Label = labels[i]
+		count, err := hintMenuItems(item.Items, itemPath(path, item), marker,
+			alphabet, item.Reserved)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// Returns the item's label with the marker inserted before the item's
+// preset (if it has one and the label isn't already marked). The preset
+// must be in the scope's alphabet, and literal markers (marker + marker)
+// in the label are never used for it.
+func presetLabel(item *MenuItem, marker byte, alphabet string) (string,
+	error) {
+	if item.Preset == "" || AcceleratorsX([]string{item.Label},
+		marker)[0] != 0 {
+		return item.Label, nil
+	}
+	preset := []rune(strings.ToUpper(item.Preset))
+	if len(preset) != 1 || preset[0] == rune(marker) {
+		return "", fmt.Errorf("invalid preset %q", item.Preset)
+	}
+	if !strings.ContainsRune(alphabet, preset[0]) {
+		return "", fmt.Errorf("preset %q not in alphabet %q", item.Preset,
+			alphabet)
+	}
+	chars := []rune(item.Label)
+	for i := 0; i < len(chars); i++ {
+		if chars[i] == rune(marker) && i+1 < len(chars) &&
+			chars[i+1] == rune(marker) {
+			i++ // skip literal marker
+			continue
+		}
+		if unicode.ToUpper(chars[i]) == preset[0] {
+			return string(chars[:i]) + string(marker) + string(chars[i:]),
+				nil
+		}
+	}
+	return "", fmt.Errorf("preset %q not in label %q", item.Preset,
+		item.Label)
+}
+
+// Returns the alphabet without any of the reserved keys.
+func withoutReserved(alphabet, reserved string) string {
+	if reserved == "" {
+		return alphabet
+	}
+	reserved = strings.ToUpper(reserved)
+	return strings.Map(func(c rune) rune {
+		if strings.ContainsRune(reserved, c) {
+			return -1
+		}
+		return c
+	}, alphabet)
+}
+
+func itemPath(path string, item *MenuItem) string {
+	name := item.ID
+	if name == "" {
+		name = item.Label
+	}
+	if path == "" {
+		return name
+	}
+	return path + "/" + name
+}
+
+func scopePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+type jsonField struct {
+	key   string
+	value any
+}
+
+func (menu *Menu) MarshalJSON() ([]byte, error) {
+	return marshalJSONFields([]jsonField{
+		{"marker", menu.Marker},
+		{"alphabet", menu.Alphabet},
+		{"reserved", menu.Reserved},
+		{"items", menu.Items},
+	}, menu.Extra)
+}
+
+func (menu *Menu) UnmarshalJSON(data []byte) error {
+	extra, err := unmarshalJSONFields(data, map[string]any{
+		"marker":   &menu.Marker,
+		"alphabet": &menu.Alphabet,
+		"reserved": &menu.Reserved,
+		"items":    &menu.Items,
+	})
+	menu.Extra = extra
+	return err
+}
+
+func (item *MenuItem) MarshalJSON() ([]byte, error) {
+	return marshalJSONFields([]jsonField{
+		{"id", item.ID},
+		{"label", item.Label},
+		{"preset", item.Preset},
+		{"skip", item.Skip},
+		{"reserved", item.Reserved},
+		{"items", item.Items},
+	}, item.Extra)
+}
+
+func (item *MenuItem) UnmarshalJSON(data []byte) error {
+	extra, err := unmarshalJSONFields(data, map[string]any{
+		"id":       &item.ID,
+		"label":    &item.Label,
+		"preset":   &item.Preset,
+		"skip":     &item.Skip,
+		"reserved": &item.Reserved,
+		"items":    &item.Items,
+	})
+	item.Extra = extra
+	return err
+}
+
+// Writes the known fields in order (omitting empty or false ones except
+// for "label"), followed by the extra fields in key order.
+func marshalJSONFields(fields []jsonField, extra map[string]any) ([]byte,
+	error) {
+	known := len(fields)
+	keys := maps.Keys(extra)
+	slices.Sort(keys)
+	for _, key := range keys {
+		fields = append(fields, jsonField{key, extra[key]})
+	}
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false) // keep markers readable
+	buffer.WriteByte('{')
+	for i, field := range fields {
+		if i < known && isEmptyField(field) {
+			continue
+		}
+		if buffer.Len() > 1 {
+			buffer.WriteByte(',')
+		}
+		if err := encoder.Encode(field.key); err != nil {
+			return nil, err
+		}
+		buffer.Truncate(buffer.Len() - 1) // drop Encode's newline
+		buffer.WriteByte(':')
+		if err := encoder.Encode(field.value); err != nil {
+			return nil, fmt.Errorf("%q: %w", field.key, err)
+		}
+		buffer.Truncate(buffer.Len() - 1)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+func isEmptyField(field jsonField) bool {
+	switch value := field.value.(type) {
+	case string:
+		return value == "" && field.key != "label"
+	case bool:
+		return !value
+	case []*MenuItem:
+		return len(value) == 0
+	}
+	return false
+}
+
+// Decodes the known fields into their targets and returns the rest.
+func unmarshalJSONFields(data []byte, known map[string]any) (map[string]any,
+	error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	var extra map[string]any
+	for key, raw := range fields {
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		if target, found := known[key]; found {
+			if err := decoder.Decode(target); err != nil {
+				return nil, fmt.Errorf("%q: %w", key, err)
+			}
+			continue
+		}
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("%q: %w", key, err)
+		}
+		if extra == nil {
+			extra = make(map[string]any)
+		}
+		extra[key] = value
+	}
+	return extra, nil
+}
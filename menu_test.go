@@ -0,0 +1,188 @@
+package accelhint
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const menuJSON = `{
+  "reserved": "q",
+  "items": [
+    {
+      "id": "file",
+      "label": "File",
+      "items": [
+        {
+          "id": "file.new",
+          "label": "New",
+          "icon": "document-new"
+        },
+        {
+          "id": "file.open",
+          "label": "Open",
+          "preset": "p"
+        },
+        {
+          "label": "-"
+        },
+        {
+          "id": "file.recent",
+          "label": "Recent Files",
+          "skip": true
+        },
+        {
+          "id": "file.quit",
+          "label": "Quit",
+          "shortcut": {
+            "ctrl": true,
+            "key": "Q"
+          }
+        }
+      ]
+    },
+    {
+      "id": "edit",
+      "label": "Edit",
+      "reserved": "N",
+      "items": [
+        {
+          "label": "New Tab"
+        }
+      ]
+    },
+    {
+      "id": "quick",
+      "label": "Quick Start"
+    }
+  ],
+  "version": 2
+}
+`
+
+func TestMenu1(t *testing.T) {
+	menu, err := ReadMenuJSON(strings.NewReader(menuJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	count, err := menu.Hint()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if count != 7 {
+		t.Errorf("expected 7 accelerated got %d", count)
+	}
+	expected := []string{"&File", "&Edit", "Quick &Start"}
+	for i, item := range menu.Items {
+		if item.Label != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], item.Label)
+		}
+	}
+	expected = []string{"&New", "O&pen", "-", "Recent Files", "&Quit"}
+	for i, item := range menu.Items[0].Items {
+		if item.Label != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], item.Label)
+		}
+	}
+	if label := menu.Items[1].Items[0].Label; label != "New &Tab" {
+		t.Errorf("expected \"New &Tab\", got %q", label)
+	}
+	var buffer bytes.Buffer
+	if err = menu.WriteJSON(&buffer); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedJSON := strings.NewReplacer(`"File"`, `"&File"`,
+		`"New"`, `"&New"`, `"Open"`, `"O&pen"`, `"Quit"`, `"&Quit"`,
+		`"Edit"`, `"&Edit"`, `"New Tab"`, `"New &Tab"`,
+		`"Quick Start"`, `"Quick &Start"`).Replace(menuJSON)
+	if buffer.String() != expectedJSON {
+		t.Errorf("expected\n%s\ngot\n%s", expectedJSON, buffer.String())
+	}
+}
+
+func TestMenu2(t *testing.T) {
+	const menuYAML = `marker: _
+items:
+  - id: view
+    label: View
+    items:
+      - label: Zoom In
+        tooltip: Make it bigger
+      - label: Zoom Out
+  - label: Help
+    x-order: 9
+`
+	menu, err := ReadMenuYAML(strings.NewReader(menuYAML))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err = menu.Hint(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var buffer bytes.Buffer
+	if err = menu.WriteYAML(&buffer); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := strings.NewReplacer("View", "_View", "Zoom In", "_Zoom In",
+		"Zoom Out", "Zoom _Out", "Help", "_Help").Replace(menuYAML)
+	if buffer.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buffer.String())
+	}
+}
+
+func TestMenuBad1(t *testing.T) {
+	menu := &Menu{Items: []*MenuItem{
+		{ID: "edit", Label: "Edit", Items: []*MenuItem{
+			{Label: "&Copy"},
+			{Label: "Cut", Preset: "c"},
+		}},
+	}}
+	_, err := menu.Hint()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "menu edit: duplicate accelerator 'C' in rows 0 and 1"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err)
+	}
+	menu.Items[0].Items[1].Preset = "x"
+	_, err = menu.Hint()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected = "menu edit/Cut: preset \"x\" not in label \"Cut\""
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err)
+	}
+}
+
+func TestMenuBadPresets(t *testing.T) {
+	for i, test := range []struct {
+		item     *MenuItem
+		expected string
+	}{
+		{&MenuItem{Label: "Find && Replace", Preset: "&"},
+			`menu Find && Replace: invalid preset "&"`},
+		{&MenuItem{Label: "Cut (-)", Preset: "-"},
+			`menu Cut (-): preset "-" not in alphabet "ABCDEFGHIJKLMNOPQRSTUVW` +
+				`YZ123456789"`},
+		{&MenuItem{Label: "Cut", Preset: "x"},
+			`menu Cut: preset "x" not in alphabet "ABCDEFGHIJKLMNOPQRSTUVW` +
+				`YZ123456789"`},
+	} {
+		menu := &Menu{Reserved: "X", Items: []*MenuItem{test.item}}
+		if _, err := menu.Hint(); err == nil {
+			t.Errorf("#%d: expected an error", i)
+		} else if err.Error() != test.expected {
+			t.Errorf("#%d: expected %q, got %q", i, test.expected, err)
+		}
+	}
+	menu := &Menu{Marker: "_", Items: []*MenuItem{
+		{Label: "Save__As", Preset: "a"}}}
+	if _, err := menu.Hint(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := "S_ave__As"; menu.Items[0].Label != expected {
+		t.Errorf("expected %q, got %q", expected, menu.Items[0].Label)
+	}
+}
@@ -0,0 +1,353 @@
+// Copyright © 2023 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package accelhint
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// The default Options.ShortcutSeparator, e.g., for "Open\tCtrl+O".
+	ShortcutSeparator = "\t"
+	// The characters Options.AvoidDescenders forbids.
+	Descenders = "gjpqy"
+)
+
+// Patterns for Options.Placeholders.
+var (
+	// Matches printf-style verbs, e.g., "%s", "%-5d", "%.2f", "%%".
+	PrintfPlaceholders = regexp.MustCompile(
+		`%[-+# 0]*(\d+|\*)?(\.(\d+|\*))?[a-zA-Z%]`)
+	// Matches Qt-style numbered arguments, e.g., "%1", "%L2".
+	QtPlaceholders = regexp.MustCompile(`%L?\d+`)
+	// Matches braced placeholders, e.g., "{name}", "{0}", "{{count}}".
+	BracePlaceholders = regexp.MustCompile(`\{+[^{}]*\}+`)
+	// Matches shell-style variables, e.g., "$(file)", "${file}", "$file".
+	ShellPlaceholders = regexp.MustCompile(
+		`\$(\([^()]*\)|\{[^{}]*\}|[A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// Options control how accelerators are chosen. Create Options with
+// NewOptions and then change the fields as required.
+//
+// Choices are made using whole number costs so the same items and Options
+// give the same results on every platform. The items' claims on their
+// chars (e.g., a first char over a word start) decide first, then how
+// early the chars are in the items, and then how late the items are (so
+// later items win when items compete for too few chars), with none of
+// these outweighing another. Of assignments that are still equally good,
+// the earliest item that differs gets its better char (e.g., "&Save" and
+// "S&ave-As"), so every optimal Assigner gives the same result.
+type Options struct {
+	// The accelerator marker (only ASCII is allowed).
+	Marker byte
+
+	// The candidate characters (which must be unique and uppercase).
+	Alphabet string
+
+	// Treats lowercase and uppercase characters as different accelerators
+	// (e.g., for terminal UIs where 'a' and 'A' are different keys), so the
+	// Alphabet may have both (e.g., CaseSensitiveAlphabet), and items'
+	// characters are only matched as they are.
+	CaseSensitive bool
+
+	// Reports which characters start a word: these are preferred over
+	// characters in the middle of words.
+	WordStarts WordStartsFunc
+
+	// Separates an item's label from its shortcut text (e.g.,
+	// "Open\tCtrl+O"): the separator and the text following it are never
+	// candidates but are preserved. Set it to "" if items have no shortcuts.
+	ShortcutSeparator string
+
+	// Match placeholders in items that are templates (e.g., "Open %s"):
+	// characters in placeholders are never candidates, so markers are only
+	// ever inserted into the template's own text. See, for example,
+	// PrintfPlaceholders and BracePlaceholders.
+	Placeholders []*regexp.Regexp
+
+	// Maps items to the characters they should use if possible, in order of
+	// preference (e.g., "Exit" → "X"). Unlike presets these are strongly
+	// favoured rather than forced (and are ignored for items with presets):
+	// use Honoured to find out which preferences were met. Items are keyed
+	// by their label without markers or shortcut text (e.g., "Save As" for
+	// "Save &As\tCtrl+Shift+S"), as for the other maps keyed by item.
+	Preferred map[string]string
+
+	// Allows a grapheme cluster of a letter followed by combining marks
+	// (e.g., a decomposed "é") to use the letter as its accelerator.
+	// Otherwise only clusters of a single character are candidates. Either
+	// way, markers are only ever inserted before whole clusters.
+	BaseLetters bool
+
+	// Allows accented characters to use their unaccented letter as their
+	// accelerator if the alphabet doesn't have them (e.g., "Édition" can use
+	// E and "Año" can use N). The marker is still inserted before the
+	// accented character. This implies BaseLetters.
+	FoldDiacritics bool
+
+	// Applied to each of an item's grapheme clusters before matching it
+	// against the alphabet, so that, for example, with NFC a decomposed "é"
+	// (e followed by a combining acute) is the same as a precomposed "é",
+	// and with NFKC "①" is the same as "1". Items are returned in their
+	// original form and keys for the maps are normalized too. The default is
+	// NoNormalization.
+	Normalization Normalization
+
+	// Characters that must never be used. These are compared with the
+	// characters as they appear in items, so, for example, "l" forbids a
+	// lowercase L but not an uppercase one.
+	Forbidden string
+
+	// Maps items to characters they must never use (compared as for
+	// Forbidden).
+	ForbiddenFor map[string]string
+
+	// Also forbids the Descenders (which may render poorly when underlined).
+	AvoidDescenders bool
+
+	// Maps items to how important they are (the default is 0, and higher is
+	// more important): when characters are scarce, more important items get
+	// the better characters, and the least important items lose their
+	// accelerators first. Each step up doubles the strength of an item's
+	// claim (up to ten steps either way). When there are more items than
+	// characters in the alphabet, only the most important are considered
+	// (and of equally important items, the earliest).
+	Importance map[string]int
+
+	// Reports which items must never get an accelerator (e.g., separators,
+	// disabled headings, or decorative items): these take up none of the
+	// alphabet and are returned unchanged. The default is SkipSeparators;
+	// set it to nil to consider every item.
+	Skip SkipFunc
+
+	// Solves the assignment of characters to items: the default is
+	// Hungarian, with JonkerVolgenant as an optimal alternative, and Greedy
+	// as a fast but not necessarily optimal one.
+	Assigner Assigner
+}
+
+// Normalization is a Unicode normalization form.
+type Normalization uint8
+
+const (
+	NoNormalization Normalization = iota
+	NFC                           // canonical composition
+	NFKC                          // compatibility composition
+)
+
+func (normalization Normalization) String() string {
+	switch normalization {
+	case NFC:
+		return "NFC"
+	case NFKC:
+		return "NFKC"
+	}
+	return "none"
+}
+
+// Returns the text normalized.
+func (normalization Normalization) normalize(text string) string {
+	switch {
+	case len(text) == 1: // ASCII is the same in every form
+		return text
+	case normalization == NFC:
+		return norm.NFC.String(text)
+	case normalization == NFKC:
+		return norm.NFKC.String(text)
+	}
+	return text
+}
+
+// A SkipFunc returns true if the item at the given row must never get an
+// accelerator.
+type SkipFunc func(row int, item string) bool
+
+// Returns Options using Marker, Alphabet, SpaceWordStarts,
+// ShortcutSeparator, SkipSeparators, and Hungarian, i.e., which behave the
+// same as Hinted.
+func NewOptions() *Options {
+	return &Options{Marker: Marker, Alphabet: Alphabet,
+		WordStarts: SpaceWordStarts, ShortcutSeparator: ShortcutSeparator,
+		Skip: SkipSeparators, Assigner: Hungarian}
+}
+
+// Returns true if the item is empty or a separator, i.e., only has
+// whitespace and dashes (e.g., "-", "---", or "──").
+func SkipSeparators(_ int, item string) bool {
+	return strings.TrimFunc(item, func(c rune) bool {
+		return unicode.IsSpace(c) || unicode.Is(unicode.Pd, c) || c == '─'
+	}) == ""
+}
+
+// Returns items with markers to indicate accelerators, and the number
+// accelerated. Use marker + marker for literal markers.
+// See also Hinted and HintedX.
+func (options *Options) Hinted(items []string) ([]string, int, error) {
+	alphabet := []rune(options.Alphabet)
+	assignment, err := assign(items, options, alphabet)
+	if err != nil {
+		return nil, 0, err
+	}
+	return applyIndexes(items, options, alphabet, assignment)
+}
+
+// Returns a slice the same length as hinted with true for each item whose
+// accelerator is one of its Preferred characters, or that has no
+// preferences.
+func (options *Options) Honoured(hinted []string) []bool {
+	honoured := make([]bool, 0, len(hinted))
+	accels := AcceleratorsX(hinted, options.Marker)
+	for i, item := range hinted {
+		preferred := options.Preferred[options.key(item)]
+		honoured = append(honoured, preferred == "" ||
+			(accels[i] != 0 && strings.ContainsRune(options.uppers(
+				preferred), options.upper(accels[i]))))
+	}
+	return honoured
+}
+
+// Returns the characters the item with the given key must not use.
+func (options *Options) forbidden(key string) string {
+	forbidden := options.Forbidden + options.ForbiddenFor[key]
+	if options.AvoidDescenders {
+		forbidden += Descenders
+	}
+	return forbidden
+}
+
+// Returns the indexes of the items to consider (in order), i.e., all of
+// them that aren't skipped, or if there are more than size, the size most
+// important. The indexes are appended to rows.
+func (options *Options) rows(rows []int, items []string, size int) []int {
+	for row, item := range items {
+		if options.Skip == nil || !options.Skip(row, item) {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) <= size {
+		return rows
+	}
+	if len(options.Importance) > 0 {
+		importance := make([]int, len(items))
+		for row, item := range items {
+			importance[row] = options.Importance[options.key(item)]
+		}
+		slices.SortStableFunc(rows, func(a, b int) bool {
+			return importance[a] > importance[b]
+		})
+	}
+	rows = rows[:size]
+	slices.Sort(rows)
+	return rows
+}
+
+// Returns the char in uppercase unless the Options are CaseSensitive.
+func (options *Options) upper(c rune) rune {
+	if options.CaseSensitive {
+		return c
+	}
+	return unicode.ToUpper(c)
+}
+
+// Returns the text in uppercase unless the Options are CaseSensitive.
+func (options *Options) uppers(text string) string {
+	if options.CaseSensitive {
+		return text
+	}
+	return strings.ToUpper(text)
+}
+
+// Returns true if any of the Options' maps keyed by item are in use.
+func (options *Options) keyed() bool {
+	return len(options.Preferred) > 0 || len(options.ForbiddenFor) > 0 ||
+		len(options.Importance) > 0
+}
+
+// Returns the key to use for the item in the Options' maps.
+func (options *Options) key(item string) string {
+	return options.Normalization.normalize(unhinted(withoutShortcut(item,
+		options.ShortcutSeparator), options.Marker))
+}
+
+// Returns a string that is the same for Options that hint items the same
+// way and true, or "" and false if the Options can't be fingerprinted
+// because a function-valued field isn't one of the package's own (e.g., it
+// is a closure, whose captured state can't be compared), or the Assigner
+// isn't.
+func (options *Options) fingerprint() (string, bool) {
+	wordStarts, ok := namedFunc(options.WordStarts, SpaceWordStarts,
+		PunctuationWordStarts, CamelCaseWordStarts, UAX29WordStarts)
+	if !ok {
+		return "", false
+	}
+	skip, ok := namedFunc(options.Skip, SkipSeparators)
+	if !ok {
+		return "", false
+	}
+	assigner, ok := assignerIdentity(options.Assigner)
+	if !ok {
+		return "", false
+	}
+	var out strings.Builder
+	fmt.Fprintf(&out, "%q%q%t%q%q%t%t%t%d", options.Marker,
+		options.Alphabet, options.CaseSensitive, options.ShortcutSeparator,
+		options.Forbidden, options.AvoidDescenders, options.BaseLetters,
+		options.FoldDiacritics, options.Normalization)
+	for _, pattern := range options.Placeholders {
+		fmt.Fprintf(&out, "%q", pattern.String())
+	}
+	for _, items := range []map[string]string{options.Preferred,
+		options.ForbiddenFor} {
+		keys := maps.Keys(items)
+		slices.Sort(keys)
+		out.WriteByte('|')
+		for _, key := range keys {
+			fmt.Fprintf(&out, "%q%q", key, items[key])
+		}
+	}
+	keys := maps.Keys(options.Importance)
+	slices.Sort(keys)
+	out.WriteByte('|')
+	for _, key := range keys {
+		fmt.Fprintf(&out, "%q%d", key, options.Importance[key])
+	}
+	fmt.Fprintf(&out, "|%d|%d|%s|", wordStarts, skip, assigner)
+	return out.String(), true
+}
+
+// Returns the (1-based) index of the function in named (or 0 if it is nil)
+// and true, or 0 and false if it isn't nil or one of the named functions.
+func namedFunc[F any](function F, named ...F) (int, bool) {
+	value := reflect.ValueOf(function)
+	if value.IsNil() {
+		return 0, true
+	}
+	for i, name := range named {
+		if value.Pointer() == reflect.ValueOf(name).Pointer() {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// Returns the assigner's type, and address too if it is a pointer, and
+// true, or "" and false if it isn't nil or one of the package's assigners.
+func assignerIdentity(assigner Assigner) (string, bool) {
+	switch assigner := assigner.(type) {
+	case nil, hungarian, jonkerVolgenant, greedy:
+		return fmt.Sprintf("%T", assigner), true
+	case *jonkerVolgenant: // its buffers don't affect its results
+		return fmt.Sprintf("%T@%p", assigner, assigner), true
+	}
+	return "", false
+}
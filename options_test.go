@@ -0,0 +1,295 @@
+package accelhint
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestPreferred(t *testing.T) {
+	original := []string{"Save", "Save As...", "Export", "Exit\tCtrl+Q",
+		"Ex&ecute"}
+	options := NewOptions()
+	options.Preferred = map[string]string{
+		"Save As...": "A",
+		"Exit":       "X",
+		"Export":     "XP",
+		"Execute":    "C", // ignored because of the preset
+	}
+	expected := []string{"&Save", "Save &As...", "Ex&port", "E&xit\tCtrl+Q",
+		"Ex&ecute"}
+	hinted, _, err := options.Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	expectedHonoured := []bool{true, true, true, true, false}
+	if honoured := options.Honoured(hinted); !slices.Equal(honoured,
+		expectedHonoured) {
+		t.Errorf("expected %v, got %v", expectedHonoured, honoured)
+	}
+	options.Preferred = map[string]string{"Save": "Q", "Exit": "Z"}
+	hinted, _, err = options.Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected = []string{"&Save", "Save &As...", "E&xport", "Ex&it\tCtrl+Q",
+		"Ex&ecute"}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	expectedHonoured = []bool{false, true, true, false, true}
+	if honoured := options.Honoured(hinted); !slices.Equal(honoured,
+		expectedHonoured) {
+		t.Errorf("expected %v, got %v", expectedHonoured, honoured)
+	}
+}
+
+func TestForbidden(t *testing.T) {
+	original := []string{"Copy", "Cut", "Paste", "Print", "Layout",
+		"Gallery"}
+	expected := []string{"&Copy", "C&ut", "&Paste", "P&rint", "&Layout",
+		"&Gallery"}
+	hinted, _, err := Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	options := NewOptions()
+	options.AvoidDescenders = true
+	options.Forbidden = "Il1"
+	options.ForbiddenFor = map[string]string{"Gallery": "G"}
+	expected = []string{"&Copy", "C&ut", "&Paste", "P&rint", "&Layout",
+		"G&allery"}
+	hinted, _, err = options.Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	hinted, _, err = options.Hinted([]string{"Lg", "g1", "Il"})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected = []string{"&Lg", "g1", "Il"}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+}
+
+func TestImportance(t *testing.T) {
+	original := []string{"Copy", "Cut", "Close", "Clone", "Cancel"}
+	expected := []string{"C&opy", "C&ut", "&Close", "C&lone", "C&ancel"}
+	hinted, _, err := Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	options := NewOptions()
+	options.Importance = map[string]int{"Cut": 2, "Clone": 1, "Copy": -1}
+	expected = []string{"C&opy", "&Cut", "C&lose", "Clo&ne", "C&ancel"}
+	hinted, _, err = options.Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+}
+
+func TestImportanceScarce(t *testing.T) {
+	original := []string{"Alpha", "Beta", "Gamma", "Delta", "Copy"}
+	options := NewOptions()
+	options.Alphabet = "ABC"
+	for i, test := range []struct {
+		importance map[string]int
+		expected   []string
+	}{
+		{nil, []string{"&Alpha", "&Beta", "Gamma", "Delta", "Copy"}},
+		{map[string]int{"Copy": 1},
+			[]string{"&Alpha", "&Beta", "Gamma", "Delta", "&Copy"}},
+		{map[string]int{"Alpha": -1},
+			[]string{"Alpha", "&Beta", "G&amma", "Delta", "Copy"}},
+	} {
+		options.Importance = test.importance
+		hinted, _, err := options.Hinted(original)
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %s", i, err)
+		}
+		if !slices.Equal(hinted, test.expected) {
+			t.Errorf("#%d: expected %q, got %q", i, test.expected, hinted)
+		}
+	}
+}
+
+func TestSkip(t *testing.T) {
+	original := []string{"Open", "-", "Options", "── Recent ──", "",
+		"Output", "Other"} // the heading isn't a separator
+	options := NewOptions()
+	options.Alphabet = "OPTU"
+	expected := []string{"&Open", "-", "O&ptions", "── Recen&t ──", "",
+		"O&utput", "Other"}
+	hinted, count, err := options.Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if count != 4 {
+		t.Errorf("expected 4 accelerated got %d", count)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	options.Skip = func(row int, item string) bool {
+		return row == 0 || row == 3 || SkipSeparators(row, item)
+	}
+	expected = []string{"Open", "-", "&Options", "── Recent ──", "",
+		"O&utput", "O&ther"}
+	hinted, _, err = options.Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+}
+
+func TestFoldDiacritics(t *testing.T) {
+	original := []string{"Édition", "Año", "Ñandú", "État", "Øre"}
+	expected := []string{"É&dition", "&Año", "Ña&ndú", "É&tat", "Ø&re"}
+	hinted, _, err := Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	options := NewOptions()
+	options.FoldDiacritics = true
+	expected = []string{"&Édition", "&Año", "&Ñandú", "É&tat", "Ø&re"}
+	hinted, _, err = options.Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	hinted, _, err = options.Hinted([]string{"E\u0301tat", "Xy"}) // NFD
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected = []string{"&E\u0301tat", "&Xy"}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	options.Alphabet = "ÉE"
+	hinted, _, err = options.Hinted([]string{"Été", "Ete"})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected = []string{"&Été", "&Ete"}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+}
+
+func TestNormalization(t *testing.T) {
+	nfc := []string{"\u00c9cran", "Exit"}
+	nfd := []string{"E\u0301cran", "Exit"}
+	options := NewOptions()
+	options.Alphabet = "\u00c9EXCR"
+	hinted, _, err := options.Hinted(nfd)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected := []string{"E\u0301&cran", "&Exit"} // É isn't matched
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	options.Normalization = NFC
+	hinted, _, err = options.Hinted(nfd)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected = []string{"&E\u0301cran", "&Exit"} // in the original form
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	options.Preferred = map[string]string{"\u00c9cran": "R"}
+	for i, items := range [][]string{nfc, nfd} {
+		hinted, _, err = options.Hinted(items)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		expected = []string{[]string{"\u00c9c&ran", "E\u0301c&ran"}[i],
+			"&Exit"}
+		if !slices.Equal(hinted, expected) {
+			t.Errorf("expected %q, got %q", expected, hinted)
+		}
+	}
+	original := []string{"① First", "Second", "ﬁle"}
+	options = NewOptions()
+	for _, normalization := range []Normalization{NoNormalization, NFC,
+		NFKC} {
+		options.Normalization = normalization
+		hinted, _, err = options.Hinted(original)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		expected = []string{"① &First", "&Second", "ﬁ&le"}
+		if normalization == NFKC {
+			expected[0] = "&① First"
+		}
+		if !slices.Equal(hinted, expected) {
+			t.Errorf("%s expected %q, got %q", normalization, expected,
+				hinted)
+		}
+	}
+}
+
+func TestCaseSensitive(t *testing.T) {
+	original := []string{"Add", "add", "&Bold", "&bold"}
+	options := NewOptions()
+	options.Alphabet = "ABab"
+	if hinted, _, err := options.Hinted(original); err == nil {
+		t.Errorf("expected duplicate accelerator error, got %q", hinted)
+	}
+	options.CaseSensitive = true
+	hinted, count, err := options.Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected := []string{"&Add", "&add", "&Bold", "&bold"}
+	if count != 4 || !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q (%d)", expected, hinted, count)
+	}
+	original = []string{"Add", "add", "All", "all"}
+	options.Alphabet = CaseSensitiveAlphabet
+	options.Preferred = map[string]string{"all": "L"} // there's no L
+	hinted, _, err = options.Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected = []string{"&Add", "a&dd", "A&ll", "&all"}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	expectedHonoured := []bool{true, true, true, false}
+	if honoured := options.Honoured(hinted); !slices.Equal(honoured,
+		expectedHonoured) {
+		t.Errorf("expected %v, got %v", expectedHonoured, honoured)
+	}
+	if diagnostics := options.Check(hinted); len(diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diagnostics)
+	}
+	options.CaseSensitive = false
+	if diagnostics := options.Check(hinted); len(diagnostics) == 0 {
+		t.Error("expected duplicate accelerator diagnostics")
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright © 2023 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package accelhint
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Score is a breakdown of how the items in a hinted list are accelerated:
+// on their First character, at the start of a word (WordStart), anywhere
+// else (MidWord), or not at all (Unassigned). Empty items and separators
+// (see SkipSeparators) aren't counted.
+type Score struct {
+	First      int
+	WordStart  int
+	MidWord    int
+	Unassigned int
+}
+
+// Returns a single number for the score (higher is better), using the same
+// relative preferences as Hinted: first characters are best, then word
+// starts, then anywhere.
+func (score Score) Total() int {
+	return 4*score.First + 2*score.WordStart + score.MidWord
+}
+
+func (score Score) String() string {
+	return fmt.Sprintf("first=%d word-start=%d mid-word=%d unassigned=%d "+
+		"total=%d", score.First, score.WordStart, score.MidWord,
+		score.Unassigned, score.Total())
+}
+
+// QualityReport compares the Score of a hinted list (Actual) with the Best
+// Score achievable by HintedX for the same items without any presets.
+// BestHinted holds the items as hinted for the Best Score.
+type QualityReport struct {
+	Actual     Score
+	Best       Score
+	BestHinted []string
+}
+
+// Returns true if the actual hinting scores worse than the best (e.g.,
+// because a preset forced a poor choice).
+func (report QualityReport) Suboptimal() bool {
+	return report.Actual.Total() < report.Best.Total()
+}
+
+// Returns the Score for the hinted items using the given marker.
+// See also CompareQuality and Options.Quality.
+func Quality(hinted []string, marker byte) Score {
+	options := NewOptions()
+	options.Marker = marker
+	return options.Quality(hinted)
+}
+
+// Returns the Score for the hinted items using the Options' Marker, as
+// Quality does, but using the Options' ShortcutSeparator, Skip, and
+// WordStarts settings, so items are scored as Hinted would score them.
+// See also Quality and Options.CompareQuality.
+func (options *Options) Quality(hinted []string) Score {
+	var score Score
+	m := string(options.Marker)
+	var starts []bool
+	for row, item := range hinted {
+		if options.Skip != nil && options.Skip(row, item) {
+			continue
+		}
+		chars := []rune(strings.ReplaceAll(withoutShortcut(item,
+			options.ShortcutSeparator), m+m, placeholder))
+		i := slices.Index(chars, rune(options.Marker))
+		if i == -1 || markedChar(string(chars[i+1:])) == 0 {
+			score.Unassigned++
+			continue
+		}
+		chars = slices.Delete(chars, i, i+1)
+		for isFormatChar(chars[i]) {
+			i++
+		}
+		starts = options.WordStarts(starts, chars)
+		start := starts[i]
+		j := i - 1 // skip any format chars (e.g., bidi marks) before it
+		for j > -1 && isFormatChar(chars[j]) {
+			start = start || starts[j]
+			j--
+		}
+		switch {
+		case j == -1:
+			score.First++
+		case start:
+			score.WordStart++
+		default:
+			score.MidWord++
+		}
+	}
+	return score
+}
+
+// Returns a QualityReport comparing the hinted items (using the given
+// marker) with the best hinting of the items with their markers removed,
+// using the given alphabet.
+// See also Quality and Options.CompareQuality.
+func CompareQuality(hinted []string, marker byte, alphabet string) (
+	QualityReport, error) {
+	options := NewOptions()
+	options.Marker = marker
+	options.Alphabet = alphabet
+	return options.CompareQuality(hinted)
+}
+
+// Returns a QualityReport comparing the hinted items with the best hinting
+// of the items with their markers removed, as CompareQuality does, but
+// hinting and scoring them using the Options.
+// See also CompareQuality and Options.Quality.
+func (options *Options) CompareQuality(hinted []string) (QualityReport,
+	error) {
+	best, _, err := options.Hinted(Unhinted(hinted, options.Marker))
+	if err != nil {
+		return QualityReport{}, err
+	}
+	return QualityReport{Actual: options.Quality(hinted),
+		Best: options.Quality(best), BestHinted: best}, nil
+}
+
+// Returns the hinted items with their accelerator markers removed (literal
+// marker + marker pairs are kept).
+func Unhinted(hinted []string, marker byte) []string {
+	items := make([]string, 0, len(hinted))
+	for _, item := range hinted {
+		items = append(items, unhinted(item, marker))
+	}
+	return items
+}
+
+func unhinted(item string, marker byte) string {
+	m := string(marker)
+	mm := m + m
+	if !strings.Contains(item, m) {
+		return item
+	}
+	parts := strings.Split(item, mm)
+	for i, part := range parts {
+		parts[i] = strings.ReplaceAll(part, m, "")
+	}
+	return strings.Join(parts, mm)
+}
@@ -0,0 +1,85 @@
+package accelhint
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestQuality1(t *testing.T) {
+	hinted := []string{"&Undo", "Find &Again", "F&ind && Replace", "Copy",
+		"", "Cut&", "-", "──"}
+	expected := Score{First: 1, WordStart: 1, MidWord: 1, Unassigned: 2}
+	if score := Quality(hinted, '&'); score != expected {
+		t.Errorf("expected %v, got %v", expected, score)
+	}
+	if total := expected.Total(); total != 7 {
+		t.Errorf("expected 7, got %d", total)
+	}
+}
+
+func TestQualitySeparators(t *testing.T) {
+	expected := Score{MidWord: 1}
+	if score := Quality([]string{"Save-&As", "-"}, '&'); score != expected {
+		t.Errorf("expected %v, got %v", expected, score)
+	}
+}
+
+func TestOptionsQuality(t *testing.T) {
+	hinted := []string{"Save-&As | Ctrl+&S", "Zoom/&Pan", "──", "E&xit"}
+	expected := Score{MidWord: 3}
+	if score := Quality(hinted, '&'); score != expected {
+		t.Errorf("expected %v, got %v", expected, score)
+	}
+	options := NewOptions()
+	options.ShortcutSeparator = " | "
+	options.WordStarts = PunctuationWordStarts
+	options.Skip = func(row int, item string) bool {
+		return row == 3 || SkipSeparators(row, item)
+	}
+	expected = Score{WordStart: 2}
+	if score := options.Quality(hinted); score != expected {
+		t.Errorf("expected %v, got %v", expected, score)
+	}
+	report, err := options.CompareQuality(hinted)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !report.Suboptimal() || report.Best.First != 2 {
+		t.Errorf("expected a suboptimal report, got %v", report)
+	}
+}
+
+func TestQuality2(t *testing.T) {
+	hinted := []string{"&Undo", "&Redo", "C&opy", "Cu&t", "&Paste"}
+	report, err := CompareQuality(hinted, '&', Alphabet)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !report.Suboptimal() {
+		t.Errorf("expected suboptimal, got %v", report)
+	}
+	expected := []string{"&Undo", "&Redo", "C&opy", "&Cut", "&Paste"}
+	if !slices.Equal(report.BestHinted, expected) {
+		t.Errorf("expected %q, got %q", expected, report.BestHinted)
+	}
+	if report.Actual.Total() != 14 || report.Best.Total() != 17 {
+		t.Errorf("expected totals 14 and 17, got %v and %v",
+			report.Actual, report.Best)
+	}
+	report, err = CompareQuality(expected, '&', Alphabet)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if report.Suboptimal() {
+		t.Errorf("expected optimal, got %v", report)
+	}
+}
+
+func TestUnhinted(t *testing.T) {
+	hinted := []string{"&Undo", "F&ind && Replace", "&&&Ampersand", "Cut"}
+	expected := []string{"Undo", "Find && Replace", "&&Ampersand", "Cut"}
+	if items := Unhinted(hinted, '&'); !slices.Equal(items, expected) {
+		t.Errorf("expected %q, got %q", expected, items)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package accelhint
+
+func init() {
+	raceEnabled = true // sync.Pool drops items at random under the race detector
+}
@@ -0,0 +1,145 @@
+// Copyright © 2023 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package accelhint
+
+import (
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// ANSI escape sequences used by Segments' Underlined and Bold methods. Each
+// resets only its own attribute so the surrounding style is kept.
+const (
+	ansiUnderline    = "\x1b[4m"
+	ansiNotUnderline = "\x1b[24m"
+	ansiBold         = "\x1b[1m"
+	ansiNotBold      = "\x1b[22m"
+)
+
+// Segments holds a hinted item split around its accelerator for display
+// without a marker, e.g., in a terminal UI: "Save &As" → "Save ", "A", "s".
+// Key is the accelerated grapheme cluster, or "" if the item has no
+// accelerator (in which case the whole item is the Prefix). Literal markers
+// (marker + marker) are unescaped, and any shortcut text is in the Suffix.
+type Segments struct {
+	Prefix string
+	Key    string
+	Suffix string
+}
+
+// Returns the segments of the hinted strings assuming '&' is the
+// accelerator marker.
+// See also SplitX and Options.HintedSegments.
+func Split(hinted []string) []Segments {
+	return SplitX(hinted, Marker)
+}
+
+// Returns the segments of the hinted strings using the given accelerator
+// marker.
+// See also Split and Options.HintedSegments.
+func SplitX(hinted []string, marker byte) []Segments {
+	segments := make([]Segments, 0, len(hinted))
+	for _, hint := range hinted {
+		segments = append(segments, split(hint, marker))
+	}
+	return segments
+}
+
+// Returns items split around the accelerators chosen for them using the
+// Options, and the number accelerated.
+// See also Split and SplitX.
+func (options *Options) HintedSegments(items []string) ([]Segments, int,
+	error) {
+	hinted, count, err := options.Hinted(items)
+	if err != nil {
+		return nil, 0, err
+	}
+	return SplitX(hinted, options.Marker), count, nil
+}
+
+// Returns the hinted string split at its first unescaped marker.
+func split(hint string, marker byte) Segments {
+	var segments Segments
+	var text strings.Builder
+	for i := 0; i < len(hint); i++ {
+		if hint[i] == marker && i+1 < len(hint) {
+			if hint[i+1] == marker {
+				text.WriteByte(marker)
+				i++
+				continue
+			}
+			if segments.Key == "" {
+				if key, j := markedCluster(hint, i+1); key != "" {
+					text.WriteString(hint[i+1 : j]) // e.g., bidi marks
+					segments.Prefix = text.String()
+					text.Reset()
+					segments.Key = key
+					i = j + len(key) - 1
+					continue
+				}
+			}
+		}
+		text.WriteByte(hint[i])
+	}
+	if segments.Key == "" {
+		segments.Prefix = text.String()
+	} else {
+		segments.Suffix = text.String()
+	}
+	return segments
+}
+
+// Returns the first grapheme cluster from the byte index in the hint on
+// that isn't a format char (see isFormat) and its index, or "" if there's
+// none.
+func markedCluster(hint string, i int) (string, int) {
+	for i < len(hint) {
+		cluster, _, _, _ := uniseg.FirstGraphemeClusterInString(hint[i:], -1)
+		if !isFormat(cluster) {
+			return cluster, i
+		}
+		i += len(cluster)
+	}
+	return "", i
+}
+
+// Returns the item without a marker, e.g., "Save As".
+func (segments Segments) String() string {
+	return segments.Prefix + segments.Key + segments.Suffix
+}
+
+// Returns the item with its Key rendered by the given function, e.g.,
+// a lipgloss Style's Render method.
+// See also Bracketed, Underlined, and Bold.
+func (segments Segments) Rendered(render func(key string) string) string {
+	if segments.Key == "" {
+		return segments.Prefix
+	}
+	return segments.Prefix + render(segments.Key) + segments.Suffix
+}
+
+// Returns the item with its Key in square brackets, e.g., "Save [A]s".
+// See also Rendered.
+func (segments Segments) Bracketed() string {
+	return segments.Rendered(func(key string) string {
+		return "[" + key + "]"
+	})
+}
+
+// Returns the item with its Key underlined using ANSI escape sequences.
+// See also Bold and Rendered.
+func (segments Segments) Underlined() string {
+	return segments.Rendered(func(key string) string {
+		return ansiUnderline + key + ansiNotUnderline
+	})
+}
+
+// Returns the item with its Key in bold using ANSI escape sequences.
+// See also Underlined and Rendered.
+func (segments Segments) Bold() string {
+	return segments.Rendered(func(key string) string {
+		return ansiBold + key + ansiNotBold
+	})
+}
@@ -0,0 +1,62 @@
+package accelhint
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestSplit(t *testing.T) {
+	hinted := []string{"&Save", "Save &As...\tCtrl+Shift+S", "Find && R&eplace",
+		"-", "", "No Key", "A && B", "Trailing&", "&Écran", "&\u200fX",
+		"&\u200f"}
+	expected := []Segments{{"", "S", "ave"},
+		{"Save ", "A", "s...\tCtrl+Shift+S"}, {"Find & R", "e", "place"},
+		{"-", "", ""}, {"", "", ""}, {"No Key", "", ""}, {"A & B", "", ""},
+		{"Trailing&", "", ""}, {"", "É", "cran"}, {"\u200f", "X", ""},
+		{"&\u200f", "", ""}}
+	segments := Split(hinted)
+	if !slices.Equal(segments, expected) {
+		t.Errorf("expected %q, got %q", expected, segments)
+	}
+	segments = SplitX([]string{"_Open", "Save __ _As"}, GtkMarker)
+	expected = []Segments{{"", "O", "pen"}, {"Save _ ", "A", "s"}}
+	if !slices.Equal(segments, expected) {
+		t.Errorf("expected %q, got %q", expected, segments)
+	}
+}
+
+func TestRendered(t *testing.T) {
+	segments, count, err := NewOptions().HintedSegments([]string{"Save",
+		"Save As", "-"})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 accelerated got %d", count)
+	}
+	for i, test := range []struct {
+		render   func(Segments) string
+		expected []string
+	}{
+		{Segments.String, []string{"Save", "Save As", "-"}},
+		{Segments.Bracketed, []string{"[S]ave", "Save [A]s", "-"}},
+		{Segments.Underlined, []string{"\x1b[4mS\x1b[24mave",
+			"Save \x1b[4mA\x1b[24ms", "-"}},
+		{Segments.Bold, []string{"\x1b[1mS\x1b[22mave",
+			"Save \x1b[1mA\x1b[22ms", "-"}},
+		{func(segments Segments) string {
+			return segments.Rendered(func(key string) string {
+				return "<u>" + key + "</u>"
+			})
+		}, []string{"<u>S</u>ave", "Save <u>A</u>s", "-"}},
+	} {
+		rendered := make([]string, 0, len(segments))
+		for _, segment := range segments {
+			rendered = append(rendered, test.render(segment))
+		}
+		if !slices.Equal(rendered, test.expected) {
+			t.Errorf("#%d: expected %q, got %q", i, test.expected, rendered)
+		}
+	}
+}
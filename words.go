@@ -0,0 +1,81 @@
+// Copyright © 2023 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package accelhint
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// A WordStartsFunc returns a slice the same length as chars with true for
+// each char that starts a word. The starts buffer is reused if it is big
+// enough (so a Hinter needn't allocate), and may be nil.
+type WordStartsFunc func(starts []bool, chars []rune) []bool
+
+// Returns which chars start a word, treating only whitespace as separating
+// words (e.g., "Find Again" has word starts at 'F' and 'A'). This is the
+// default.
+func SpaceWordStarts(starts []bool, chars []rune) []bool {
+	return wordStarts(starts, chars, unicode.IsSpace)
+}
+
+// Returns which chars start a word, treating whitespace and punctuation
+// (including symbols) as separating words (e.g., "Save-As", "Zoom/Pan",
+// "file_name", and "(Optional)" all have two word starts).
+func PunctuationWordStarts(starts []bool, chars []rune) []bool {
+	return wordStarts(starts, chars, isSeparator)
+}
+
+// Returns which chars start a word as for PunctuationWordStarts, but also
+// treating each camelCase hump as a word start (e.g., "zoomToFit" has word
+// starts at 'z', 'T', and 'F', and "HTMLExport" at 'H' and 'E').
+func CamelCaseWordStarts(starts []bool, chars []rune) []bool {
+	starts = PunctuationWordStarts(starts, chars)
+	for i := 1; i < len(chars); i++ {
+		prev, c := chars[i-1], chars[i]
+		if unicode.IsUpper(c) && (unicode.IsLower(prev) ||
+			unicode.IsDigit(prev) || (unicode.IsUpper(prev) &&
+			i+1 < len(chars) && unicode.IsLower(chars[i+1]))) {
+			starts[i] = true
+		}
+	}
+	return starts
+}
+
+// Returns which chars start a word using the Unicode Standard Annex #29
+// word boundaries, counting only words that start with a letter or digit.
+func UAX29WordStarts(starts []bool, chars []rune) []bool {
+	starts = resized(starts, len(chars))
+	for i := range starts {
+		starts[i] = false
+	}
+	text := string(chars)
+	state := -1
+	i := 0
+	for text != "" {
+		var word string
+		word, text, state = uniseg.FirstWordInString(text, state)
+		c, _ := utf8.DecodeRuneInString(word)
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			starts[i] = true
+		}
+		i += utf8.RuneCountInString(word)
+	}
+	return starts
+}
+
+func wordStarts(starts []bool, chars []rune,
+	isSeparator func(rune) bool) []bool {
+	starts = resized(starts, len(chars))
+	for i, c := range chars {
+		starts[i] = !isSeparator(c) && (i == 0 || isSeparator(chars[i-1]))
+	}
+	return starts
+}
+
+func isSeparator(c rune) bool {
+	return unicode.IsSpace(c) || unicode.IsPunct(c) || unicode.IsSymbol(c)
+}
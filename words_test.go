@@ -0,0 +1,75 @@
+package accelhint
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestWordStarts(t *testing.T) {
+	tests := []struct {
+		wordStarts WordStartsFunc
+		text       string
+		expected   string // ^ marks word starts
+	}{
+		{SpaceWordStarts, "Find Again", "^    ^    "},
+		{SpaceWordStarts, "Save-As", "^      "},
+		{PunctuationWordStarts, "Save-As", "^    ^ "},
+		{PunctuationWordStarts, "Zoom/Pan", "^    ^  "},
+		{PunctuationWordStarts, "file_name", "^    ^   "},
+		{PunctuationWordStarts, "(Optional)", " ^        "},
+		{CamelCaseWordStarts, "zoomToFit", "^   ^ ^  "},
+		{CamelCaseWordStarts, "HTMLExport", "^   ^     "},
+		{CamelCaseWordStarts, "Page2Up", "^    ^ "},
+		{UAX29WordStarts, "Save-As (Optional)", "^    ^   ^        "},
+		{UAX29WordStarts, "file_name can't", "^         ^    "},
+	}
+	for _, test := range tests {
+		starts := test.wordStarts(nil, []rune(test.text))
+		marks := make([]rune, 0, len(starts))
+		for _, start := range starts {
+			if start {
+				marks = append(marks, '^')
+			} else {
+				marks = append(marks, ' ')
+			}
+		}
+		if string(marks) != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.text, test.expected,
+				string(marks))
+		}
+	}
+}
+
+func TestWordStartsHinted(t *testing.T) {
+	original := []string{"Save", "Save-As", "Zoom/Pan", "Zoom", "(Optional)"}
+	expected := []string{"&Save", "S&ave-As", "&Zoom/Pan", "Z&oom",
+		"(O&ptional)"}
+	hinted, _, err := Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	options := NewOptions()
+	options.WordStarts = PunctuationWordStarts
+	expected = []string{"&Save", "Save-&As", "Zoom/&Pan", "&Zoom",
+		"(&Optional)"}
+	hinted, _, err = options.Hinted(original)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+	options.WordStarts = CamelCaseWordStarts
+	hinted, _, err = options.Hinted([]string{"Zoom", "zoomToFit"})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	expected = []string{"&Zoom", "zoom&ToFit"}
+	if !slices.Equal(hinted, expected) {
+		t.Errorf("expected %q, got %q", expected, hinted)
+	}
+}